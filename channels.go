@@ -0,0 +1,41 @@
+package promise
+
+// Return a channel on which the outcome of the given promise is sent once it
+// settles, so that it can be waited on in a select statement. The channel is
+// buffered, so the result is never lost if no one is receiving yet, and it is
+// closed after the result has been sent.
+func ToChan(thenable Thenable) <-chan Result {
+	results := make(chan Result, 1)
+
+	watch(thenable, func(result Result) {
+		results <- result
+
+		close(results)
+	})
+
+	return results
+}
+
+// Create a promise which is completed with the first value received from the
+// given channel, or rejected with ErrChannelClosed if the channel is closed
+// before a value is received. A goroutine waits on the channel until then;
+// cancelling the promise releases it without receiving anything.
+func FromChan[T any](values <-chan T) Thenable {
+	promise := completable(nil, nil)
+
+	go func() {
+		select {
+		case value, ok := <-values:
+			if !ok {
+				promise.TryReject(ErrChannelClosed)
+
+				return
+			}
+
+			promise.TryComplete(value)
+		case <-promise.Done():
+		}
+	}()
+
+	return promise
+}
@@ -0,0 +1,87 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// Validate that the outcome of a promise can be received from a channel, and
+// that Done() can be used in a select statement.
+func TestToChan(test *testing.T) {
+	var expected = errors.New("Expected error!")
+
+	completed := Promise()
+	rejected := Promise()
+	cancelled := Promise()
+
+	completions := ToChan(completed)
+	rejections := ToChan(rejected)
+	cancellations := ToChan(cancelled)
+
+	select {
+	case <-completions:
+		test.Fatalf("Expected nothing to be received before completion")
+	case <-completed.(*CompletablePromise).Done():
+		test.Fatalf("Expected Done() not to be closed before completion")
+	default:
+	}
+
+	completed.Complete(10)
+	rejected.Reject(expected)
+	cancelled.Cancel()
+
+	if result := <-completions; result != (Result{Value: 10, State: FULFILLED}) {
+		test.Fatalf("Expected the result of completion, saw %v", result)
+	}
+
+	if result := <-rejections; result != (Result{Cause: expected, State: REJECTED}) {
+		test.Fatalf("Expected the result of rejection, saw %v", result)
+	}
+
+	if result := <-cancellations; result != (Result{Cause: ErrCancelled, State: CANCELLED}) {
+		test.Fatalf("Expected the result of cancellation, saw %v", result)
+	}
+
+	if _, ok := <-completions; ok {
+		test.Fatalf("Expected the channel to be closed after the result")
+	}
+
+	select {
+	case <-completed.(*CompletablePromise).Done():
+	case <-time.After(time.Second):
+		test.Fatalf("Expected Done() to be closed after completion")
+	}
+
+	if result := <-ToChan(Completed(20)); result.Value != 20 {
+		test.Fatalf("Expected the result of a completed promise, saw %v", result)
+	}
+}
+
+// Validate that a promise can be created from a channel.
+func TestFromChan(test *testing.T) {
+	values := make(chan int)
+	received := FromChan(values)
+
+	values <- 10
+
+	if value, err := received.Get(); value != 10 || err != nil {
+		test.Fatalf("Expected 10, saw %v, %v", value, err)
+	}
+
+	closed := make(chan int)
+
+	close(closed)
+
+	if _, err := FromChan(closed).Get(); err != ErrChannelClosed {
+		test.Fatalf("Expected ErrChannelClosed, saw %v", err)
+	}
+
+	abandoned := FromChan(make(chan string)).(Completable)
+
+	abandoned.Cancel()
+
+	if !abandoned.Cancelled() {
+		test.Fatalf("Expected the promise to be cancelled")
+	}
+}
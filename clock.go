@@ -0,0 +1,72 @@
+package promise
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// A source of the current time, and of timers, for the promises in this
+// package which settle once time has passed, such as those created by
+// Retry(), WithTimeout() and Delay(). It can be replaced with SetClock(), so
+// that such code can be tested without waiting. GetTimeout() only waits for a
+// promise, rather than creating one, and always waits in real time.
+type Clock interface {
+	// Return the current time.
+	Now() time.Time
+
+	// Run the task once the duration has elapsed, unless the timer returned
+	// is stopped first. The task must not run on the calling goroutine before
+	// AfterFunc() has returned.
+	AfterFunc(duration time.Duration, task func()) Timer
+}
+
+// A timer created by a Clock. A *time.Timer is one.
+type Timer interface {
+	// Prevent the timer from firing. Returns false if it has already fired,
+	// or been stopped.
+	Stop() bool
+}
+
+// The Clock which uses the time package, which is the default.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) AfterFunc(duration time.Duration, task func()) Timer {
+	return time.AfterFunc(duration, task)
+}
+
+// An atomic.Value must always hold the same concrete type, which the clocks
+// themselves do not.
+type clockHolder struct {
+	clock Clock
+}
+
+var clock atomic.Value
+
+func init() {
+	clock.Store(clockHolder{SystemClock})
+}
+
+// Return the Clock in use.
+func now() Clock {
+	return clock.Load().(clockHolder).clock
+}
+
+// Set the Clock used throughout this package, returning the previous one so
+// that it may be restored with:
+//
+//	defer promise.SetClock(promise.SetClock(fake))
+//
+// Setting nil restores SystemClock.
+func SetClock(replacement Clock) Clock {
+	if replacement == nil {
+		replacement = SystemClock
+	}
+
+	return clock.Swap(clockHolder{replacement}).(clockHolder).clock
+}
@@ -0,0 +1,325 @@
+package promise
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Observe the outcome of a thenable, once it settles, and return a function
+// which stops observing it. For a CompletablePromise, stopping removes the
+// observer from its dependencies, so that it does not linger after it is no
+// longer wanted.
+func watch(thenable Thenable, observe func(Result)) func() {
+	completable, ok := thenable.(*CompletablePromise)
+
+	if !ok {
+		thenable.Then(func(value interface{}) interface{} {
+			observe(Result{Value: value, State: FULFILLED})
+
+			return nil
+		}).Catch(func(cause error) {
+			observe(Result{Cause: cause, State: REJECTED})
+		})
+
+		return func() {}
+	}
+
+	var stopped uint32
+
+	watcher := completable.derive(nil, callbacks{
+		compute: func(value interface{}) (interface{}, error) {
+			observe(Result{Value: value, State: FULFILLED})
+
+			return nil, nil
+		},
+		handle: func(cause error) (interface{}, error) {
+			observe(Result{Cause: cause, State: REJECTED})
+
+			return nil, nil
+		},
+	})
+
+	pending, ok := watcher.(*CompletablePromise)
+
+	if !ok {
+		return func() {}
+	}
+
+	pending.OnCancel(func() {
+		if atomic.LoadUint32(&stopped) == 0 {
+			observe(Result{Cause: ErrCancelled, State: CANCELLED})
+		}
+	})
+
+	return func() {
+		atomic.StoreUint32(&stopped, 1)
+
+		pending.Cancel()
+	}
+}
+
+// A set of observers of a number of thenables, which can all be stopped at
+// once, for instance when one of the thenables wins a race.
+type watches struct {
+	mutex   sync.Mutex
+	stopped bool
+	stops   []func()
+}
+
+// Observe a thenable, as watch() does, unless the watches have been stopped.
+func (watches *watches) watch(thenable Thenable, observe func(Result)) {
+	stop := watch(thenable, observe)
+
+	watches.mutex.Lock()
+
+	if watches.stopped {
+		watches.mutex.Unlock()
+
+		stop()
+
+		return
+	}
+
+	watches.stops = append(watches.stops, stop)
+
+	watches.mutex.Unlock()
+}
+
+// Determine whether or not the watches have been stopped, in which case there
+// is no sense in watching anything else.
+func (watches *watches) done() bool {
+	watches.mutex.Lock()
+
+	defer watches.mutex.Unlock()
+
+	return watches.stopped
+}
+
+// Stop every observer, including any added hereafter.
+func (watches *watches) stop() {
+	watches.mutex.Lock()
+
+	if watches.stopped {
+		watches.mutex.Unlock()
+
+		return
+	}
+
+	stops := watches.stops
+
+	watches.stopped = true
+	watches.stops = nil
+
+	watches.mutex.Unlock()
+
+	for _, stop := range stops {
+		stop()
+	}
+}
+
+// Combine the given promises as a single promise which produces a slice of
+// values. Given an arbitrarily long list of promises (as variadic arguments)
+// combine all of the promises to a single promise which transforms all of the
+// results of the promises into a slice (as []interface{}), in the same order.
+// If any of the promises is rejected, the combined promise is rejected with
+// the same cause as soon as it is, and the rest are no longer observed. A
+// promise which is cancelled counts as being rejected with ErrCancelled. Given
+// no promises, the combined promise is completed with an empty slice.
+func All(thenables ...Thenable) Thenable {
+	values := make([]interface{}, len(thenables))
+
+	if len(thenables) == 0 {
+		return Completed(values)
+	}
+
+	all := completable(nil, nil)
+	watches := new(watches)
+
+	all.OnCancel(watches.stop)
+
+	var failed uint32
+	var remaining = int64(len(thenables))
+
+	for i, each := range thenables {
+		if watches.done() {
+			break
+		}
+
+		i := i
+
+		watches.watch(each, func(result Result) {
+			if result.Cause != nil {
+				if atomic.CompareAndSwapUint32(&failed, 0, 1) {
+					watches.stop()
+
+					all.TryReject(result.Cause)
+				}
+
+				return
+			}
+
+			// Each value has a slot of its own, and the last one to be filled
+			// in is the one which completes the combined promise, so there's
+			// no need for a lock here.
+			values[i] = result.Value
+
+			if atomic.AddInt64(&remaining, -1) == 0 {
+				all.TryComplete(values)
+			}
+		})
+	}
+
+	return all
+}
+
+// Create a promise which settles as the first of the given promises to settle
+// does. Once one has, the rest are no longer observed. A promise which is
+// cancelled counts as being rejected with ErrCancelled. Given no promises, the
+// promise returned never settles. Cancelling the promise returned stops
+// observing the given promises, but does not cancel them.
+func Race(thenables ...Thenable) Thenable {
+	race := completable(nil, nil)
+	watches := new(watches)
+
+	race.OnCancel(watches.stop)
+
+	var won uint32
+
+	for _, each := range thenables {
+		if watches.done() {
+			break
+		}
+
+		watches.watch(each, func(result Result) {
+			if !atomic.CompareAndSwapUint32(&won, 0, 1) {
+				return
+			}
+
+			watches.stop()
+
+			race.resolve(result.Value, result.Cause)
+		})
+	}
+
+	return race
+}
+
+// Create a promise which is completed with the value of the first of the
+// given promises to be completed. Rejections are ignored, unless every one of
+// the promises is rejected, in which case the promise returned is rejected with
+// a *MultiError holding every cause. Once one has been completed, the rest
+// are no longer observed. A promise which is cancelled counts as being
+// rejected with ErrCancelled. Given no promises, the promise returned is
+// rejected immediately.
+func Any(thenables ...Thenable) Thenable {
+	if len(thenables) == 0 {
+		return Rejected(&MultiError{Errors: []*IndexedError{}})
+	}
+
+	first := completable(nil, nil)
+	watches := new(watches)
+
+	first.OnCancel(watches.stop)
+
+	var mutex sync.Mutex
+	var won uint32
+
+	causes := make([]*IndexedError, len(thenables))
+	remaining := len(thenables)
+
+	for i, each := range thenables {
+		if watches.done() {
+			break
+		}
+
+		i := i
+
+		watches.watch(each, func(result Result) {
+			if result.Cause == nil {
+				if atomic.CompareAndSwapUint32(&won, 0, 1) {
+					watches.stop()
+
+					first.TryComplete(result.Value)
+				}
+
+				return
+			}
+
+			mutex.Lock()
+
+			causes[i] = &IndexedError{Index: i, Cause: result.Cause}
+			remaining--
+			rejected := remaining == 0
+
+			mutex.Unlock()
+
+			if rejected {
+				first.TryReject(&MultiError{Errors: causes})
+			}
+		})
+	}
+
+	return first
+}
+
+// Create a promise which is completed once every one of the given promises
+// has settled, however it settled. Its value is a []Result of the outcome of
+// each of the promises, in the same order.
+func AllSettled(thenables ...Thenable) Thenable {
+	results := make([]Result, len(thenables))
+
+	if len(thenables) == 0 {
+		return Completed(results)
+	}
+
+	all := completable(nil, nil)
+
+	var remaining = int64(len(thenables))
+
+	for i, each := range thenables {
+		i := i
+
+		watch(each, func(result Result) {
+			results[i] = result
+
+			if atomic.AddInt64(&remaining, -1) == 0 {
+				all.TryComplete(results)
+			}
+		})
+	}
+
+	return all
+}
+
+// Combine the given promises as a single promise which produces a slice of
+// values, as All() does, except that it waits for every one of the promises to
+// settle. If any of them is rejected, the combined promise is rejected with a
+// *MultiError holding the cause of every rejection.
+func AllCollectErrors(thenables ...Thenable) Thenable {
+	return AllSettled(thenables...).ThenTry(func(results interface{}) (interface{}, error) {
+		return Collect(results.([]Result))
+	})
+}
+
+// Collect the values of a number of results, such as those produced by
+// AllSettled(). If any of them was rejected, or cancelled, return a
+// *MultiError holding the cause of every rejection instead.
+func Collect(results []Result) ([]interface{}, error) {
+	values := make([]interface{}, len(results))
+
+	var causes []*IndexedError
+
+	for i, result := range results {
+		if result.Cause != nil {
+			causes = append(causes, &IndexedError{Index: i, Cause: result.Cause})
+		}
+
+		values[i] = result.Value
+	}
+
+	if causes != nil {
+		return nil, &MultiError{Errors: causes}
+	}
+
+	return values, nil
+}
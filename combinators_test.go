@@ -0,0 +1,265 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+)
+
+// Validate that All() handles no promises, and rejects as soon as any of its
+// promises is rejected.
+func TestAllRejected(test *testing.T) {
+	var expected = errors.New("Expected error!")
+
+	if value, _ := All().Get(); value == nil || len(value.([]interface{})) != 0 {
+		test.Fatalf("Expected All() of nothing to be an empty slice, saw %v", value)
+	}
+
+	pending := Promise()
+	rejected := Promise()
+
+	all := All(pending, rejected)
+
+	rejected.Reject(expected)
+
+	if _, err := all.Get(); err != expected {
+		test.Fatalf("Expected the cause of the rejection, saw %v", err)
+	}
+
+	if len(pending.(*CompletablePromise).dependencies()) != 0 {
+		test.Fatalf("Expected the pending promise to no longer be observed")
+	}
+}
+
+// The implementation of All() as a chain of Combine() calls, which it once was,
+// for comparison.
+func allCombined(thenables ...Thenable) Thenable {
+	var cursor Thenable
+
+	for _, each := range thenables {
+		if cursor == nil {
+			cursor = each.Then(func(value interface{}) interface{} {
+				return []interface{}{value}
+			})
+
+			continue
+		}
+
+		cursor = func(promise Thenable) Thenable {
+			return cursor.Combine(func(left interface{}) Thenable {
+				values, _ := left.([]interface{})
+
+				return promise.Then(func(right interface{}) interface{} {
+					return append(values, right)
+				})
+			})
+		}(each)
+	}
+
+	return cursor
+}
+
+const ALLINPUTS = 10000
+
+// Combine a number of promises which are completed afterward.
+func benchmarkAll(bench *testing.B, all func(...Thenable) Thenable) {
+	for i := 0; i < bench.N; i++ {
+		promises := make([]Completable, ALLINPUTS)
+		thenables := make([]Thenable, ALLINPUTS)
+
+		for j := range promises {
+			promises[j] = Promise()
+			thenables[j] = promises[j]
+		}
+
+		combined := all(thenables...)
+
+		for j, promise := range promises {
+			promise.Complete(j)
+		}
+
+		values, _ := combined.Get()
+
+		if len(values.([]interface{})) != ALLINPUTS {
+			bench.Fatalf("Expected %d values", ALLINPUTS)
+		}
+	}
+}
+
+func BenchmarkAll(bench *testing.B) {
+	benchmarkAll(bench, All)
+}
+
+func BenchmarkAllCombined(bench *testing.B) {
+	benchmarkAll(bench, allCombined)
+}
+
+// Validate that Race() settles as the first promise to settle does, and stops
+// observing the rest.
+func TestRace(test *testing.T) {
+	var expected = errors.New("Expected error!")
+
+	slow := Promise()
+	fast := Promise()
+
+	race := Race(slow, fast)
+
+	fast.Complete(10)
+
+	if value, _ := race.Get(); value != 10 {
+		test.Fatalf("Expected the value of the first promise, saw %v", value)
+	}
+
+	if len(slow.(*CompletablePromise).dependencies()) != 0 {
+		test.Fatalf("Expected the losing promise to no longer be observed")
+	}
+
+	slow.Complete(20)
+
+	if _, err := Race(Promise(), Rejected(expected)).Get(); err != expected {
+		test.Fatalf("Expected the cause of the first rejection, saw %v", err)
+	}
+
+	if value, _ := Race(Completed(30), Completed(40)).Get(); value != 30 {
+		test.Fatalf("Expected the first of the completed promises, saw %v", value)
+	}
+
+	pending := Promise()
+
+	cancelled := Race(pending)
+
+	cancelled.(Completable).Cancel()
+
+	if len(pending.(*CompletablePromise).dependencies()) != 0 {
+		test.Fatalf("Expected a cancelled race to stop observing its promises")
+	}
+}
+
+// Validate that Any() ignores rejections, unless every promise is rejected.
+func TestAny(test *testing.T) {
+	var expected = errors.New("Expected error!")
+
+	rejected := Promise()
+	completed := Promise()
+
+	first := Any(rejected, completed)
+
+	rejected.Reject(expected)
+
+	if first.Rejected() {
+		test.Fatalf("Expected Any() to ignore a single rejection")
+	}
+
+	completed.Complete(10)
+
+	if value, _ := first.Get(); value != 10 {
+		test.Fatalf("Expected the value of the completed promise, saw %v", value)
+	}
+
+	cancelled := Promise()
+
+	cancelled.Cancel()
+
+	_, err := Any(Rejected(expected), cancelled).Get()
+
+	var multi *MultiError
+
+	if !errors.As(err, &multi) || len(multi.Errors) != 2 {
+		test.Fatalf("Expected a *MultiError of both causes, saw %v", err)
+	}
+
+	if multi.Cause(0) != expected || multi.Cause(1) != ErrCancelled {
+		test.Fatalf("Expected a *MultiError to record the index of each cause")
+	}
+
+	if !errors.Is(err, expected) || !errors.Is(err, ErrCancelled) {
+		test.Fatalf("Expected a *MultiError to match its causes")
+	}
+
+	if _, err := Any().Get(); !errors.As(err, &multi) {
+		test.Fatalf("Expected Any() of nothing to be rejected, saw %v", err)
+	}
+}
+
+// Validate that AllSettled() waits for every promise, however it settles.
+func TestAllSettled(test *testing.T) {
+	var expected = errors.New("Expected error!")
+
+	pending := Promise()
+	cancelled := Promise()
+
+	all := AllSettled(Completed(10), Rejected(expected), pending, cancelled)
+
+	cancelled.Cancel()
+
+	if all.Resolved() {
+		test.Fatalf("Expected AllSettled() to wait for every promise")
+	}
+
+	pending.Reject(expected)
+
+	value, err := all.Get()
+
+	if err != nil {
+		test.Fatalf("Unexpected error: %s", err)
+	}
+
+	results := value.([]Result)
+
+	expectedResults := []Result{
+		{Value: 10, State: FULFILLED},
+		{Cause: expected, State: REJECTED},
+		{Cause: expected, State: REJECTED},
+		{Cause: ErrCancelled, State: CANCELLED},
+	}
+
+	for i, result := range results {
+		if result != expectedResults[i] {
+			test.Fatalf("Expected result %v at %d, saw %v", expectedResults[i], i, result)
+		}
+	}
+
+	if value, _ := AllSettled().Get(); len(value.([]Result)) != 0 {
+		test.Fatalf("Expected AllSettled() of nothing to be empty")
+	}
+}
+
+// Validate that AllCollectErrors() waits for every promise, and records the
+// cause of every rejection.
+func TestAllCollectErrors(test *testing.T) {
+	var first = errors.New("Expected error!")
+	var second = errors.New("Another expected error!")
+
+	pending := Promise()
+
+	all := AllCollectErrors(Rejected(first), Completed(10), pending)
+
+	if all.Rejected() {
+		test.Fatalf("Expected AllCollectErrors() to wait for every promise")
+	}
+
+	pending.Reject(second)
+
+	_, err := all.Get()
+
+	var multi *MultiError
+
+	if !errors.As(err, &multi) || len(multi.Errors) != 2 {
+		test.Fatalf("Expected a *MultiError of both causes, saw %v", err)
+	}
+
+	var indexed *IndexedError
+
+	if !errors.As(err, &indexed) || indexed.Index != 0 || indexed.Cause != first {
+		test.Fatalf("Expected to find the first cause by its index, saw %v", indexed)
+	}
+
+	if multi.Cause(2) != second || multi.Cause(1) != nil {
+		test.Fatalf("Expected the second cause at index 2, saw %v", multi.Cause(2))
+	}
+
+	value, err := AllCollectErrors(Completed(10), Completed(20)).Get()
+
+	if err != nil || len(value.([]interface{})) != 2 {
+		test.Fatalf("Expected the values of every promise, saw %v (%v)", value, err)
+	}
+}
@@ -1,9 +1,11 @@
 package promise
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // Unfortunately there are no atomic operations smaller values than 32
@@ -11,27 +13,81 @@ const (
 	PENDING uint32 = iota
 	FULFILLED
 	REJECTED
+	CANCELLED
 )
 
+// A promise which is settled by whoever created it, or by the promise it was
+// composed from.
+// Everything which changes as the promise settles lives in a single node, which
+// is swapped atomically, rather than behind a lock. While the promise is
+// pending its head is a stack of the dependencies and cancellation hooks
+// registered with it, most recent first, each of which is pushed by a
+// compare-and-swap. Settling the promise swaps the whole stack for a node
+// holding its outcome, which hands the stack to whoever settled it, to notify
+// in the order it was registered. Since there is no lock, callbacks never run
+// while one is held, so they may use the promises they belong to as they
+// please, and a slow callback holds up nobody but the goroutine running it.
+//
+// Nothing is kept once the promise has settled which isn't needed to report
+// its outcome. Its dependencies and hooks are dropped along with the stack,
+// and its callbacks are claimed by whoever settles it, and released, so that
+// a promise which lives on does not keep everything composed from it alive.
 type CompletablePromise struct {
-	state        uint32
-	cause        error
-	value        interface{}
-	mutex        sync.Mutex
-	cond         *sync.Cond
-	compute      func(interface{}) interface{}
-	handle       func(error)
-	dependencies []Completable
+	head      atomic.Pointer[node]
+	consumed  uint32
+	adopting  uint32
+	done      atomic.Pointer[chan struct{}]
+	callbacks callbacks
+	unclaimed atomic.Pointer[callbacks]
+	executor  Executor
+	parent    atomic.Pointer[CompletablePromise]
 }
 
-func completable(compute func(interface{}) interface{}, handle func(error)) *CompletablePromise {
+// The callbacks which compute the outcome of a promise from the outcome of
+// the promise it depends on. Rather than computing a value, adopt creates
+// another promise, whose outcome the promise then adopts as its own.
+type callbacks struct {
+	compute func(interface{}) (interface{}, error)
+	handle  func(error) (interface{}, error)
+	adopt   func(interface{}) Thenable
+}
+
+// Either the outcome of a promise, or one of the entries in the stack of a
+// promise which is pending, which is then either a dependency or a cancellation
+// hook. A dependency which adopted the promise, rather than being composed
+// from it, takes its outcome as it is. Nodes are never modified once they have
+// been published.
+type node struct {
+	state      uint32
+	adopted    bool
+	value      interface{}
+	cause      error
+	dependency *CompletablePromise
+	hook       func()
+	next       *node
+}
+
+// A channel which is always closed, for waiting on promises which have already
+// settled.
+var closed = make(chan struct{})
+
+func init() {
+	close(closed)
+}
+
+func completable(compute func(interface{}) (interface{}, error), handle func(error) (interface{}, error)) *CompletablePromise {
+	return computed(callbacks{compute: compute, handle: handle})
+}
+
+// Create a promise whose outcome is computed by the given callbacks.
+func computed(callbacks callbacks) *CompletablePromise {
 	completable := new(CompletablePromise)
 
-	completable.cond = sync.NewCond(&completable.mutex)
-	completable.compute = compute
-	completable.handle = handle
-	completable.state = PENDING
-	completable.dependencies = make([]Completable, 0)
+	if callbacks.compute != nil || callbacks.handle != nil || callbacks.adopt != nil {
+		completable.callbacks = callbacks
+
+		completable.unclaimed.Store(&completable.callbacks)
+	}
 
 	return completable
 }
@@ -39,11 +95,49 @@ func completable(compute func(interface{}) interface{}, handle func(error)) *Com
 // Generate a new completable promise. This provides an implementation of the
 // `promise.Completable` interface which is threadsafe.
 func Promise() Completable {
-	return completable(func(x interface{}) interface{} { return x }, nil)
+	return completable(nil, nil)
+}
+
+// Generate a new completable promise, as Promise() does, for which the
+// computations of every promise composed from it run on the given executor,
+// rather than on whichever goroutine completes it. The executor is inherited
+// by the promises composed from those, and so on, so that it applies to a
+// whole chain of promises. This is an alternative to ThenOn(), which applies
+// an executor to a chain starting part of the way along.
+//
+// The order in which computations run depends on the executor. With Inline,
+// as without an executor, they run in the order they were composed, on the
+// goroutine which completed the promise, before Complete() returns. With a
+// serial queue, created with NewSerialQueue(), they also run in the order they
+// were composed, one at a time, but on the queue's goroutine, so Complete()
+// may return first. With Goroutine, or a WorkerPool of more than one worker,
+// they run concurrently, in no particular order.
+func PromiseOn(executor Executor) Completable {
+	promise := completable(nil, nil)
+
+	promise.executor = executor
+
+	return promise
+}
+
+// Return the node holding the outcome of this promise, or nil if it is still
+// pending.
+func (promise *CompletablePromise) outcome() *node {
+	head := promise.head.Load()
+
+	if head == nil || head.state == PENDING {
+		return nil
+	}
+
+	return head
 }
 
 func (promise *CompletablePromise) State() uint32 {
-	return atomic.LoadUint32(&promise.state)
+	if outcome := promise.outcome(); outcome != nil {
+		return outcome.state
+	}
+
+	return PENDING
 }
 
 // Determine if the promise has been resolved.
@@ -55,92 +149,360 @@ func (promise *CompletablePromise) Rejected() bool {
 	return promise.State() == REJECTED
 }
 
+// Determine if the promise has been cancelled.
+func (promise *CompletablePromise) Cancelled() bool {
+	return promise.State() == CANCELLED
+}
+
+// Create a promise which has already been cancelled, for derivatives of a
+// cancelled promise.
+func cancelled() *CompletablePromise {
+	promise := completable(nil, nil)
+
+	promise.head.Store(&node{state: CANCELLED, cause: ErrCancelled})
+
+	return promise
+}
+
+// Block until the promise has settled, and return its outcome.
+func (promise *CompletablePromise) wait() *node {
+	if outcome := promise.outcome(); outcome != nil {
+		return outcome
+	}
+
+	<-promise.Done()
+
+	return promise.outcome()
+}
+
 // Return the value of the promise, if it was resolved successfully, or return
 // the cause of failure if it was not. Block until the promise is either
 // completed or rejected.
 func (promise *CompletablePromise) Get() (interface{}, error) {
-	if promise.State() == PENDING {
-		promise.mutex.Lock()
+	promise.consume()
+
+	outcome := promise.wait()
+
+	return outcome.value, outcome.cause
+}
+
+// Return the value of the promise, or the cause of its rejection, if it has
+// settled, without blocking. Unlike checking State() before calling Get(),
+// there is no way for the promise to settle in between.
+func (promise *CompletablePromise) TryGet() (interface{}, error, bool) {
+	outcome := promise.outcome()
 
-		for promise.State() == PENDING {
-			// wait unlocks its associated mutex (incase you were wondering)
-			// so we cannot guarantee that the state has actually changed.
-			promise.cond.Wait()
+	if outcome == nil {
+		return nil, nil, false
+	}
+
+	promise.consume()
+
+	return outcome.value, outcome.cause, true
+}
+
+// Return the outcome of the promise, once it has settled. Block until the
+// promise is either completed, rejected or cancelled.
+func (promise *CompletablePromise) Result() Result {
+	promise.consume()
+
+	outcome := promise.wait()
+
+	return Result{Value: outcome.value, Cause: outcome.cause, State: outcome.state}
+}
+
+// Return a channel which is closed once the promise has settled, however it
+// settled, for use in a select statement. The outcome can then be had without
+// blocking, from Get() or Result(). The channel is only created once it is
+// asked for, since most promises are never waited on.
+func (promise *CompletablePromise) Done() <-chan struct{} {
+	for {
+		if done := promise.done.Load(); done != nil {
+			return *done
 		}
 
-		promise.mutex.Unlock()
+		if promise.outcome() != nil {
+			return closed
+		}
+
+		done := make(chan struct{})
+
+		// Once the promise has settled the channel is swapped for one which is
+		// closed already, so if this succeeds the promise has yet to do so, and
+		// will close this channel when it does.
+		if promise.done.CompareAndSwap(nil, &done) {
+			return done
+		}
+	}
+}
+
+// Wake anything waiting on the channel returned by Done(), once the promise
+// has settled.
+func (promise *CompletablePromise) wake() {
+	if done := promise.done.Swap(&closed); done != nil {
+		close(*done)
+	}
+}
+
+// Return the value of the promise, or the cause of its rejection, as Get()
+// does. If the context is done before the promise is either completed or
+// rejected, stop waiting and return the context's error instead.
+func (promise *CompletablePromise) GetContext(ctx context.Context) (interface{}, error) {
+	promise.consume()
+
+	// A promise which has already settled is preferred over a context which
+	// is done, which select would otherwise choose between at random.
+	if promise.State() != PENDING {
+		return promise.Get()
+	}
+
+	select {
+	case <-promise.Done():
+		return promise.Get()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Return the value of the promise, or the cause of its rejection, as Get()
+// does. If the promise is neither completed nor rejected within the timeout,
+// stop waiting and return a *TimeoutError instead.
+func (promise *CompletablePromise) GetTimeout(timeout time.Duration) (interface{}, error) {
+	promise.consume()
+
+	if promise.State() != PENDING {
+		return promise.Get()
+	}
+
+	timer := time.NewTimer(timeout)
+
+	defer timer.Stop()
+
+	select {
+	case <-promise.Done():
+		return promise.Get()
+	case <-timer.C:
+		return nil, &TimeoutError{Duration: timeout}
+	}
+}
+
+// Adapt a computation which can not fail to one which can.
+func infallible(compute func(interface{}) interface{}) func(interface{}) (interface{}, error) {
+	return func(value interface{}) (interface{}, error) {
+		return compute(value), nil
+	}
+}
+
+// Adapt an error handler which only observes the error to one which could
+// recover from it, but never does.
+func observer(handle func(error)) func(error) (interface{}, error) {
+	return func(cause error) (interface{}, error) {
+		handle(cause)
+
+		return nil, cause
+	}
+}
+
+// Create a promise which has already settled with the result of a computation
+// which may have failed.
+func settled(value interface{}, cause error) Thenable {
+	if cause != nil {
+		return Rejected(cause)
+	}
+
+	return Completed(value)
+}
+
+// Create a promise which has already settled with the outcome of a promise
+// composed from one which had already settled, as settled() does, except that
+// a rejection is tracked until something handles it, as it would have been had
+// the promise been composed before the one it was composed from settled.
+func tracked(value interface{}, cause error) Thenable {
+	if cause == nil {
+		return Completed(value)
 	}
 
-	return promise.value, promise.cause
+	rejected := completable(nil, nil)
+
+	rejected.head.Store(&node{state: REJECTED, cause: cause})
+
+	rejected.track()
+
+	return rejected
 }
 
-func (promise *CompletablePromise) depend(compute func(interface{}) interface{}) Thenable {
-	andThen := completable(compute, nil)
+// Compose a promise from this one, which depends on it for its value. Its
+// value is computed by the given callbacks, depending on the outcome of this
+// promise, on the given executor. Without an executor, if this promise has
+// already settled, the callbacks run immediately and the result is a promise
+// which has already settled too.
+func (promise *CompletablePromise) derive(executor Executor, callbacks callbacks) Thenable {
+	promise.consume()
+
+	var dependency *CompletablePromise
+
+	for {
+		head := promise.head.Load()
+
+		if head != nil && head.state != PENDING {
+			return promise.derived(head, dependency, executor, callbacks)
+		}
+
+		if dependency == nil {
+			dependency = computed(callbacks)
 
-	promise.dependencies = append(promise.dependencies, andThen)
+			dependency.executor = executor
+			dependency.parent.Store(promise)
+		}
 
-	return andThen
+		if promise.head.CompareAndSwap(head, &node{dependency: dependency, next: head}) {
+			return dependency
+		}
+	}
 }
 
-// The private version of this is used for `Combine` to call, so that it won't
-// attempt to acquire the mutex twice.
-func (promise *CompletablePromise) then(compute func(interface{}) interface{}) Thenable {
-	switch promise.State() {
-	case PENDING:
-		return promise.depend(compute)
-	case REJECTED:
-		return Rejected(promise.cause)
-	case FULFILLED:
-		return Completed(compute(promise.value))
+// Compose a promise from the outcome of this one, once it has settled, as
+// derive() does. The dependency, if there is one, was created while this
+// promise had not yet settled, and may be used instead of creating another.
+func (promise *CompletablePromise) derived(outcome *node, dependency *CompletablePromise, executor Executor, callbacks callbacks) Thenable {
+	if outcome.state == CANCELLED {
+		return cancelled()
 	}
 
-	panic("Invalid state")
+	if executor != nil {
+		if dependency == nil {
+			dependency = computed(callbacks)
+
+			dependency.executor = executor
+		}
+
+		dependency.parent.Store(nil)
+
+		dependency.settle(outcome.value, outcome.cause)
+
+		return dependency
+	}
+
+	if outcome.state == REJECTED {
+		if callbacks.handle != nil {
+			return tracked(tryRecover(callbacks.handle, outcome.cause))
+		}
+
+		return tracked(nil, outcome.cause)
+	}
+
+	if callbacks.compute != nil {
+		return tracked(tryCompute(callbacks.compute, outcome.value))
+	}
+
+	if callbacks.adopt != nil {
+		return tryCreate(callbacks.adopt, outcome.value)
+	}
+
+	return promise
 }
 
 // Compose this promise into one which is complete when the following code has
 // executed.
 func (promise *CompletablePromise) Then(compute func(interface{}) interface{}) Thenable {
-	switch promise.State() {
-	case PENDING:
-		promise.mutex.Lock()
-
-		defer promise.mutex.Unlock()
+	return promise.ThenTry(infallible(compute))
+}
 
-		return promise.then(compute)
-	case REJECTED:
-		return Rejected(promise.cause)
-	case FULFILLED:
-		return Completed(compute(promise.value))
-	}
+// Compose this promise into one which is complete when the following code has
+// executed, or rejected if it returns an error.
+func (promise *CompletablePromise) ThenTry(compute func(interface{}) (interface{}, error)) Thenable {
+	return promise.derive(promise.executor, callbacks{compute: compute})
+}
 
-	panic("Invalid state")
+// Compose this promise into one which is complete when the following code has
+// executed on the given executor. Promises composed from the one returned
+// inherit the executor. See PromiseOn() for the order in which computations
+// run with each kind of executor.
+func (promise *CompletablePromise) ThenOn(executor Executor, compute func(interface{}) interface{}) Thenable {
+	return promise.derive(executor, callbacks{compute: infallible(compute)})
 }
 
 // Compose this promise into another one which handles an upstream error with
 // the given handler.
 func (promise *CompletablePromise) Catch(handle func(error)) Thenable {
-	if promise.State() == PENDING {
-		promise.mutex.Lock()
+	caught := promise.Recover(observer(handle))
 
-		defer promise.mutex.Unlock()
+	// The handler has handled the rejection, even though the promise is then
+	// rejected in turn, so that rejection is not left unhandled.
+	if completable, ok := caught.(*CompletablePromise); ok {
+		completable.consume()
+	}
 
-		// Double check now that we have the lock that this is still true.
-		if promise.State() == PENDING {
-			rejectable := completable(nil, handle)
+	return caught
+}
 
-			promise.dependencies = append(promise.dependencies, rejectable)
+// Compose this promise into another one which handles an upstream error with
+// the given handler, which may recover from it. If the handler returns a nil
+// error, the derived promise is completed with the value it returns, otherwise
+// it is rejected with the error it returns.
+func (promise *CompletablePromise) Recover(handle func(error) (interface{}, error)) Thenable {
+	return promise.derive(promise.executor, callbacks{handle: handle})
+}
+
+// Compose this promise into another one which handles an upstream error by
+// creating a promise to replace this one. The derived promise is completed or
+// rejected as the created promise is.
+func (promise *CompletablePromise) RecoverWith(create func(error) Thenable) Thenable {
+	return recoverWith(promise, create)
+}
 
-			return rejectable
+// Compose this promise into another one which runs the given hook once this
+// promise is completed, rejected or cancelled, and then settles exactly as
+// this promise did. The hook runs in the same order as any other promise
+// composed from this one. If the hook panics, the derived promise is rejected
+// with a *PanicError instead. The hook runs only once, however many ways the
+// derived promise comes to settle. Cancelling the derived promise does not
+// run the hook until this promise has settled, since whatever the hook cleans
+// up after may be in use until then.
+func (promise *CompletablePromise) Finally(hook func()) Thenable {
+	// Rather than a sync.Once, which would deadlock if the hook itself
+	// cancelled the derived promise, only the first to run the hook runs it.
+	var ran uint32
+
+	run := func() {
+		if atomic.CompareAndSwapUint32(&ran, 0, 1) {
+			hook()
 		}
 	}
 
-	if promise.State() == REJECTED {
-		handle(promise.cause)
+	finally := promise.derive(promise.executor, callbacks{
+		compute: func(value interface{}) (interface{}, error) {
+			run()
+
+			return value, nil
+		},
+		handle: func(cause error) (interface{}, error) {
+			run()
+
+			return nil, cause
+		},
+	})
 
-		return Rejected(promise.cause)
+	// Cancellation runs no callbacks, so the hook must also be run when the
+	// derived promise is cancelled, once this promise has settled, which it
+	// already has if the cancellation came from this promise.
+	if completable, ok := finally.(*CompletablePromise); ok {
+		completable.OnCancel(func() {
+			watch(promise, func(Result) {
+				tryFinally(run)
+			})
+		})
 	}
 
-	return promise
+	return finally
+}
+
+// Create a promise which settles as this one does, once the duration has
+// elapsed after this one settled. If this promise is cancelled, so is the
+// promise returned, straight away. Cancelling the promise returned does not
+// cancel this one.
+func (promise *CompletablePromise) Delay(duration time.Duration) Thenable {
+	return delay(promise, duration)
 }
 
 // Error due to an illegal second state transition, after figuring out what
@@ -157,89 +519,518 @@ func panicStateComplete(rejected bool) {
 	panic(fmt.Sprintf("%s was already called on this promise", method))
 }
 
-func (promise *CompletablePromise) complete(value interface{}) interface{} {
-	// This should rarely actually be blocking, there's a separate mutex for
-	// each completable promise and the mutex is only acquired during assembly
-	// and completion.
-	promise.mutex.Lock()
+// Store the outcome of this promise, transitioning it to either FULFILLED or
+// REJECTED, and return the stack of dependencies and hooks it replaced, which
+// are then up to the caller to notify. Returns false if the promise had
+// already settled, or been cancelled, and so did not transition.
+func (promise *CompletablePromise) transition(value interface{}, cause error) (*node, bool) {
+	outcome := &node{state: FULFILLED, value: value}
+
+	if cause != nil {
+		outcome = &node{state: REJECTED, cause: cause}
+	}
+
+	for {
+		head := promise.head.Load()
+
+		if head != nil && head.state != PENDING {
+			return nil, false
+		}
 
-	defer promise.mutex.Unlock()
+		if promise.head.CompareAndSwap(head, outcome) {
+			// Once a promise has settled there's no sense in keeping the one
+			// it depended on alive, which is all this is for.
+			promise.parent.Store(nil)
 
-	composed := value
+			promise.wake()
 
-	if promise.compute != nil {
-		// Because this composition function
-		composed = promise.compute(value)
+			return head, true
+		}
 	}
+}
 
-	if promise.State() != PENDING {
-		panicStateComplete(promise.State() == REJECTED)
+// Take the callbacks of this promise, leaving a marker behind. Only whoever
+// settles the promise claims them, and only once, since a promise only
+// settles once, so the callbacks are released as soon as it does. Returns
+// false if they had already been claimed by someone else, who is then the one
+// to settle the promise.
+func (promise *CompletablePromise) claim() (callbacks, bool) {
+	unclaimed := promise.unclaimed.Load()
+
+	if unclaimed == nil {
+		return callbacks{}, true
 	}
 
-	if composed != nil {
-		promise.value = composed
+	if unclaimed == claimed || !promise.unclaimed.CompareAndSwap(unclaimed, claimed) {
+		return callbacks{}, false
 	}
 
-	atomic.StoreUint32(&promise.state, FULFILLED)
+	taken := *unclaimed
+
+	*unclaimed = callbacks{}
 
-	return composed
+	return taken, true
+}
+
+// The marker left in place of the callbacks of a promise once they have been
+// claimed.
+var claimed = new(callbacks)
+
+// A promise which is to be settled with the outcome of the promise it depends
+// on. If the promise adopted the outcome, its callbacks have already run.
+type settlement struct {
+	promise *CompletablePromise
+	value   interface{}
+	cause   error
+	adopted bool
+}
+
+// Settle a promise, and every promise which depends on it, and so on. Rather
+// than each promise settling its dependencies, which would recurse as deeply
+// as the chain of promises is long, the settlements still to be made are kept
+// on a stack of their own, and made one after another. They are made in the
+// same order as they would be by recursion: each promise's dependencies in
+// the order they were registered, each followed by its own dependencies.
+// Returns whether or not the first promise was settled, rather than having
+// already settled some other way.
+func propagate(first settlement) bool {
+	stack := settlements.Get().(*[]settlement)
+	pending, settled := first.promise.step(first, (*stack)[:0])
+
+	for len(pending) > 0 {
+		next := pending[len(pending)-1]
+
+		pending[len(pending)-1] = settlement{}
+		pending = pending[:len(pending)-1]
+
+		// A promise with an executor of its own is handed to it when its turn
+		// comes, so that it is no sooner than the promises registered before.
+		if !next.promise.inline() {
+			next.promise.executor.Execute(func() {
+				propagate(next)
+			})
+
+			continue
+		}
+
+		pending, _ = next.promise.step(next, pending)
+	}
+
+	*stack = pending
+
+	settlements.Put(stack)
+
+	return settled
+}
+
+// Stacks of settlements, which are reused, since settling a promise with many
+// dependencies would otherwise grow a new stack every time.
+var settlements = sync.Pool{
+	New: func() interface{} {
+		return new([]settlement)
+	},
+}
+
+// Transition this promise to the outcome computed by its callbacks, from the
+// outcome of the promise it depends on, and push the settlements of the
+// promises which depend on it in turn. Returns false if the promise had
+// already settled, in which case the outcome is ignored. A promise which
+// adopts another promise in its place counts as settled.
+func (promise *CompletablePromise) step(next settlement, pending []settlement) ([]settlement, bool) {
+	value, cause := next.value, next.cause
+
+	// Whoever claims the callbacks is the one to settle the promise, so that
+	// it never settles with an outcome its callbacks were skipped for, except
+	// with the outcome of a promise it adopted, whose callbacks have run.
+	// A cancelled promise has given up on its value, so there's no sense in
+	// computing it. Otherwise the callbacks are executed *before* actually
+	// storing the outcome or transitioning the state, as they may well use
+	// this promise, for instance to cancel it.
+	if !next.adopted {
+		callbacks, ok := promise.claim()
+
+		if !ok {
+			return pending, false
+		}
+
+		if promise.State() == PENDING {
+			switch {
+			case cause != nil && callbacks.handle != nil:
+				value, cause = tryRecover(callbacks.handle, cause)
+			case cause == nil && callbacks.compute != nil:
+				value, cause = tryCompute(callbacks.compute, value)
+			case cause == nil && callbacks.adopt != nil:
+				return promise.adopt(tryCreate(callbacks.adopt, value), pending), true
+			}
+		}
+	}
+
+	stack, ok := promise.transition(value, cause)
+
+	if !ok {
+		return pending, false
+	}
+
+	// The stack holds the most recent dependency first, so pushing them as
+	// they come means they are settled in the order they were registered,
+	// whether or not they have an executor.
+	var dependencies bool
+
+	for each := stack; each != nil; each = each.next {
+		if each.dependency != nil {
+			pending = append(pending, settlement{each.dependency, value, cause, each.adopted})
+
+			dependencies = true
+		}
+	}
+
+	// A rejection which nothing depends upon may never be handled.
+	if cause != nil && !dependencies {
+		promise.track()
+	}
+
+	return pending, true
+}
+
+// Settle this promise as the given thenable settles, rather than with a value
+// of its own. If the thenable is a CompletablePromise, this promise depends on
+// it as it would on a promise it was composed from, so that however long a
+// chain of adopted promises is, settling it does not recurse. Cancelling this
+// promise cancels the thenable, and vice versa.
+func (promise *CompletablePromise) adopt(thenable Thenable, pending []settlement) []settlement {
+	if thenable == nil {
+		return append(pending, settlement{promise, nil, ErrNilThenable, true})
+	}
+
+	adopted, ok := thenable.(*CompletablePromise)
+
+	if !ok {
+		if value, cause, settled := thenable.TryGet(); settled {
+			return append(pending, settlement{promise, value, cause, true})
+		}
+
+		forward(promise, thenable)
+
+		return pending
+	}
+
+	adopted.consume()
+
+	promise.OnCancel(adopted.Cancel)
+	promise.parent.Store(adopted)
+
+	for {
+		head := adopted.head.Load()
+
+		if head != nil && head.state != PENDING {
+			promise.parent.Store(nil)
+
+			if head.state == CANCELLED {
+				promise.Cancel()
+
+				return pending
+			}
+
+			return append(pending, settlement{promise, head.value, head.cause, true})
+		}
+
+		if adopted.head.CompareAndSwap(head, &node{adopted: true, dependency: promise, next: head}) {
+			return pending
+		}
+	}
+}
+
+// Split a stack into its dependencies and hooks, in the order they were
+// registered.
+func (head *node) entries() ([]*CompletablePromise, []func()) {
+	var dependencies []*CompletablePromise
+	var hooks []func()
+
+	for each := head; each != nil; each = each.next {
+		if each.dependency != nil {
+			dependencies = append(dependencies, each.dependency)
+		} else {
+			hooks = append(hooks, each.hook)
+		}
+	}
+
+	for i, j := 0, len(dependencies)-1; i < j; i, j = i+1, j-1 {
+		dependencies[i], dependencies[j] = dependencies[j], dependencies[i]
+	}
+
+	for i, j := 0, len(hooks)-1; i < j; i, j = i+1, j-1 {
+		hooks[i], hooks[j] = hooks[j], hooks[i]
+	}
+
+	return dependencies, hooks
+}
+
+// Return the dependencies of this promise, in the order they were registered,
+// while it is pending.
+func (promise *CompletablePromise) dependencies() []*CompletablePromise {
+	head := promise.head.Load()
+
+	if head == nil || head.state != PENDING {
+		return nil
+	}
+
+	dependencies, _ := head.entries()
+
+	return dependencies
+}
+
+// Determine whether or not this promise settles on whichever goroutine
+// settles the promise it depends on, as it does without an executor, or with
+// Inline.
+func (promise *CompletablePromise) inline() bool {
+	return promise.executor == nil || promise.executor == Inline
+}
+
+// Settle this promise with the outcome of the promise it depends on, on its
+// executor if it has one.
+func (promise *CompletablePromise) settle(value interface{}, cause error) {
+	if promise.inline() {
+		propagate(settlement{promise: promise, value: value, cause: cause})
+
+		return
+	}
+
+	promise.executor.Execute(func() {
+		propagate(settlement{promise: promise, value: value, cause: cause})
+	})
+}
+
+// Either complete or reject this promise, depending on the outcome, unless it
+// has already settled. Returns whether or not it was this outcome it settled
+// with.
+func (promise *CompletablePromise) resolve(value interface{}, cause error) bool {
+	if cause != nil {
+		return promise.TryReject(cause)
+	}
+
+	return promise.TryComplete(value)
+}
+
+// Complain about an attempt to settle this promise after it had already been
+// completed or rejected. Having been cancelled is no cause for complaint.
+func (promise *CompletablePromise) conflict() {
+	if state := promise.State(); state != CANCELLED {
+		panicStateComplete(state == REJECTED)
+	}
 }
 
 // Complete this promise with a given value.
 // It is considered a programming error to complete a promise multiple times.
-// The promise is to be completed once, and not thereafter.
+// The promise is to be completed once, and not thereafter. The exception is a
+// promise which has been cancelled, which the producer may not yet know about,
+// so completing a cancelled promise does nothing.
 func (promise *CompletablePromise) Complete(value interface{}) {
-	// Transition the state of this promise (which requires the lock). At this
-	// point all subsequent calls to Then() or Complete() will be called on a
-	// Completed promise, meaning they will be satisfied immediately.
-	composed := promise.complete(value)
-
-	// So now that the condition has been satisified, broadcast to all waiters
-	// that thie task is now complete. They should be in the `Get()` wait loop,
-	// above.
-	promise.cond.Broadcast()
-
-	for _, dependency := range promise.dependencies {
-		dependency.Complete(composed)
+	// Transition the state of this promise. At this point all subsequent
+	// calls to Then() or Complete() will be called on a Completed promise,
+	// meaning they will be satisfied immediately. A computation which failed
+	// rejects this promise, and so its dependencies, rather than completing
+	// it.
+	if !promise.TryComplete(value) {
+		promise.conflict()
 	}
 }
 
+// Complete this promise with a given value, unless it has already settled.
+// Returns whether or not it was this call which settled it. Unlike Complete(),
+// it is not an error to call TryComplete() on a promise which has already
+// settled, so it suits a promise which a number of producers race to settle,
+// such as a response and a timeout.
+func (promise *CompletablePromise) TryComplete(value interface{}) bool {
+	return propagate(settlement{promise: promise, value: value})
+}
+
 // Reject this promise and all of its dependencies.
 // Reject this promise, and along with it all promises which were derived from
-// it.
+// it. As with Complete(), rejecting a cancelled promise does nothing. A
+// promise derived with Recover() may be completed instead, if its handler
+// recovers from the cause.
 func (promise *CompletablePromise) Reject(cause error) {
 	if cause == nil {
 		panic(fmt.Sprintf("Reject() requires a non-nil cause"))
 	}
 
-	promise.mutex.Lock()
+	if !promise.TryReject(cause) {
+		promise.conflict()
+	}
+}
+
+// Reject this promise, unless it has already settled, as TryComplete() does.
+// Returns whether or not it was this call which settled it.
+func (promise *CompletablePromise) TryReject(cause error) bool {
+	if cause == nil {
+		panic(fmt.Sprintf("TryReject() requires a non-nil cause"))
+	}
 
+	return propagate(settlement{promise: promise, cause: cause})
+}
+
+// Settle this promise as the given thenable settles, once it does. Until then
+// this promise remains pending, so it may still be settled some other way,
+// such as by TryComplete() or Cancel(), in which case the outcome of the
+// thenable is ignored. Cancelling this promise cancels the thenable, and vice
+// versa, as with a promise returned by Combine(). As with Complete(), it is
+// an error to call CompleteWith() on a promise which has already been
+// completed or rejected, and so it is to call it twice, even while the
+// promise is still pending, since only one thenable can take its place.
+func (promise *CompletablePromise) CompleteWith(thenable Thenable) {
 	if promise.State() != PENDING {
-		panicStateComplete(promise.State() == REJECTED)
+		promise.conflict()
+
+		return
+	}
+
+	if !atomic.CompareAndSwapUint32(&promise.adopting, 0, 1) {
+		panic("CompleteWith() was already called on this promise")
+	}
+
+	for _, settlement := range promise.adopt(thenable, nil) {
+		propagate(settlement)
+	}
+}
+
+// Cancel this promise and all of its dependencies.
+// A cancelled promise is never completed or rejected, its Get() returns
+// ErrCancelled, and any Then(), Combine() or Catch() callbacks which depend on
+// it never run. Hooks registered with OnCancel() run before the cancellation
+// flows down to the promises derived from this one. Cancelling a promise which
+// has already settled does nothing.
+func (promise *CompletablePromise) Cancel() {
+	// As with settling, cancellation flows down to the dependencies of each
+	// promise from a stack, rather than by recursion.
+	pending := []*CompletablePromise{promise}
+
+	for len(pending) > 0 {
+		next := pending[len(pending)-1]
+
+		pending[len(pending)-1] = nil
+		pending = pending[:len(pending)-1]
+
+		pending = next.cancel(pending)
+	}
+}
+
+// Transition this promise to CANCELLED, if it is pending, and push the
+// promises which depend on it, to be cancelled in turn.
+func (promise *CompletablePromise) cancel(pending []*CompletablePromise) []*CompletablePromise {
+	var head *node
+
+	for {
+		head = promise.head.Load()
+
+		if head != nil && head.state != PENDING {
+			return pending
+		}
+
+		if promise.head.CompareAndSwap(head, &node{state: CANCELLED, cause: ErrCancelled}) {
+			break
+		}
+	}
+
+	parent := promise.parent.Swap(nil)
+
+	promise.claim()
+	promise.wake()
+
+	// The promise this one depends on no longer needs to settle it, and would
+	// otherwise keep it alive for no reason.
+	if parent != nil {
+		parent.detach(promise)
+	}
+
+	dependencies, hooks := head.entries()
+
+	for _, hook := range hooks {
+		hook()
+	}
+
+	for i := len(dependencies) - 1; i >= 0; i-- {
+		pending = append(pending, dependencies[i])
+	}
+
+	return pending
+}
+
+// Remove a cancelled dependency from this promise. Since the nodes of the
+// stack are never modified, the nodes above the dependency are copied onto
+// the rest of the stack below it, and swapped in as the new stack.
+func (promise *CompletablePromise) detach(dependency *CompletablePromise) {
+	for {
+		head := promise.head.Load()
+
+		// Once this promise has settled the stack is no longer its own, but
+		// belongs to whoever is notifying it.
+		if head == nil || head.state != PENDING {
+			return
+		}
+
+		var above []*node
+
+		found := head
+
+		for found != nil && found.dependency != dependency {
+			above = append(above, found)
+
+			found = found.next
+		}
+
+		if found == nil {
+			return
+		}
+
+		stack := found.next
+
+		for i := len(above) - 1; i >= 0; i-- {
+			copied := *above[i]
+
+			copied.next = stack
+			stack = &copied
+		}
+
+		if promise.head.CompareAndSwap(head, stack) {
+			return
+		}
 	}
+}
 
-	promise.cause = cause
+// Register a hook which runs if this promise is cancelled. This is how the
+// producer of a value learns that it is no longer wanted, so that it can stop
+// producing it. If the promise has already been cancelled the hook runs
+// immediately, and if it has otherwise settled the hook never runs.
+func (promise *CompletablePromise) OnCancel(hook func()) {
+	for {
+		head := promise.head.Load()
 
-	atomic.StoreUint32(&promise.state, REJECTED)
+		if head != nil && head.state != PENDING {
+			if head.state == CANCELLED {
+				hook()
+			}
 
-	promise.mutex.Unlock()
+			return
+		}
 
-	// Unlike the Complete() routine, which executes the transformation
-	// *before* actually storing the value or transitioning the state, this
-	// transitions after. The reason for that is two-fold: The return value of
-	// the handle() callback is not *stored*, and the second is that we want a
-	// promise accessed from within the Catch() handler to be in a rejected
-	// state.
-	if promise.handle != nil {
-		promise.handle(cause)
+		if promise.head.CompareAndSwap(head, &node{hook: hook, next: head}) {
+			return
+		}
 	}
+}
 
-	// Now that this is all done, notify all of the handlers that yeah, we're
-	// done.
-	promise.cond.Broadcast()
+// Cancel a thenable, if it is of a type which can be cancelled.
+func cancel(thenable Thenable) {
+	if completable, ok := thenable.(Completable); ok {
+		completable.Cancel()
+	}
+}
 
-	for _, dependency := range promise.dependencies {
-		dependency.Reject(cause)
+// Cancel the given promise when a thenable is cancelled, if it is of a type
+// which can be.
+func cancelWith(thenable Thenable, promise Completable) {
+	if completable, ok := thenable.(Completable); ok {
+		completable.OnCancel(promise.Cancel)
 	}
 }
 
@@ -249,50 +1040,44 @@ func (promise *CompletablePromise) Reject(cause error) {
 // promise which is completed when the returned promise, and this promise, are
 // completed...but no sooner.
 func (promise *CompletablePromise) Combine(create func(interface{}) Thenable) Thenable {
-	if promise.State() == PENDING {
-		promise.mutex.Lock()
-
-		defer promise.mutex.Unlock()
+	if outcome := promise.outcome(); outcome != nil && promise.executor == nil {
+		promise.consume()
 
-		if promise.State() == PENDING {
-			// So, this may seem a little whacky, but what is happening here is
-			// that seeing as there is presently no value from which to generate
-			// the new promise, a callback is registered using Then() which
-			// executes the supplied transform function, and when the promise that
-			// was returned by *that* transform produces a result, it is copied
-			// over to the placeholder thus satisfying the request.
-			placeholder := Promise()
-
-			// So, is it possible that Combine() is called, and the promise is
-			// completed while it's being combined? Should *not* be.
-			//
-			// Perhaps all access to promise.state should be atomic. We are
-			// using the double lock idiom here, after all...
-
-			// It's important that the internal then() is used here, because the
-			// external one allocates a mutex lock. sync.Mutex is not a reentrant lock
-			// type, unfortunately.
-			promise.depend(func(awaited interface{}) interface{} {
-				create(awaited).Then(func(composed interface{}) interface{} {
-					placeholder.Complete(composed)
-
-					return nil
-				}).Catch(func(err error) {
-					placeholder.Reject(err)
-				})
-
-				return nil
-			}).Catch(func(err error) {
-				placeholder.Reject(err)
-			})
-
-			return placeholder
+		switch outcome.state {
+		case FULFILLED:
+			return tryCreate(create, outcome.value)
+		case REJECTED:
+			return tracked(nil, outcome.cause)
+		case CANCELLED:
+			return cancelled()
 		}
 	}
 
-	if promise.state == REJECTED {
-		return Rejected(promise.cause)
-	} else {
-		return create(promise.value)
-	}
+	// So, this may seem a little whacky, but what is happening here is that
+	// seeing as there is presently no value from which to generate the new
+	// promise, a dependency is registered which executes the supplied
+	// transform function, and then adopts the outcome of the promise that
+	// was returned by *that* transform as its own, thus satisfying the
+	// request.
+	return promise.derive(promise.executor, callbacks{adopt: create})
+}
+
+// Settle a placeholder as the given thenable settles. Cancelling the
+// placeholder cancels the thenable, and vice versa.
+func forward(placeholder *CompletablePromise, thenable Thenable) {
+	placeholder.OnCancel(func() {
+		cancel(thenable)
+	})
+
+	forwarded := thenable.Then(func(value interface{}) interface{} {
+		propagate(settlement{promise: placeholder, value: value, adopted: true})
+
+		return nil
+	})
+
+	cancelWith(forwarded, placeholder)
+
+	forwarded.Catch(func(err error) {
+		propagate(settlement{promise: placeholder, cause: err, adopted: true})
+	})
 }
@@ -0,0 +1,445 @@
+package promise
+
+import (
+	"errors"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// Validate that dependencies registered, and cancelled, concurrently with the
+// promise being completed are each either settled or cancelled, but never
+// lost.
+func TestConcurrentDependencies(test *testing.T) {
+	for round := 0; round < 100; round++ {
+		promise := Promise()
+
+		var group sync.WaitGroup
+		var computed int64
+
+		derived := make([]Thenable, WAITERS)
+
+		for i := range derived {
+			group.Add(1)
+
+			go func(i int) {
+				defer group.Done()
+
+				derived[i] = promise.Then(func(value interface{}) interface{} {
+					atomic.AddInt64(&computed, 1)
+
+					return value
+				})
+
+				if i%2 == 0 {
+					cancel(derived[i])
+				}
+			}(i)
+		}
+
+		promise.Complete(10)
+
+		group.Wait()
+
+		var settled int64
+
+		for i, each := range derived {
+			result := each.Result()
+
+			switch {
+			case result.State == FULFILLED:
+				settled++
+			case result.State != CANCELLED || i%2 != 0:
+				test.Fatalf("Expected a cancelled or completed promise, saw %v", result)
+			}
+		}
+
+		if settled != atomic.LoadInt64(&computed) {
+			test.Fatalf("Expected %d computations, saw %d", settled, computed)
+		}
+	}
+}
+
+// The length of the chain of promises built to check that settled promises
+// do not keep the promises composed from them alive, and by how much the heap
+// may grow while the chain's first promise is kept.
+const (
+	CHAINLENGTH = 1000000
+	HEAPGROWTH  = 16 << 20
+)
+
+// Return the number of bytes allocated on the heap, after collecting garbage.
+func heapAlloc() uint64 {
+	var stats runtime.MemStats
+
+	runtime.GC()
+	runtime.ReadMemStats(&stats)
+
+	return stats.HeapAlloc
+}
+
+// Compose a promise which refers to a large buffer.
+func composeLarge(promise Thenable) Thenable {
+	buffer := make([]byte, 4*HEAPGROWTH)
+
+	return promise.Then(func(value interface{}) interface{} {
+		return len(buffer)
+	})
+}
+
+// Validate that a promise is settled by whoever claims its callbacks, and
+// never with the outcome of the promise it depends on with its callbacks
+// skipped, when that promise settles while the callbacks are running.
+func TestClaimCallbacks(test *testing.T) {
+	promise := Promise()
+
+	doubled := promise.Then(func(value interface{}) interface{} {
+		if value == 100 {
+			promise.Complete(1)
+		}
+
+		return value.(int) * 2
+	})
+
+	if !doubled.(Completable).TryComplete(100) {
+		test.Fatalf("Expected TryComplete() to settle the derived promise")
+	}
+
+	if value, _ := doubled.Get(); value != 200 {
+		test.Fatalf("Expected 100 * 2 to be 200, saw %v", value)
+	}
+
+	if value, _ := promise.Get(); value != 1 {
+		test.Fatalf("Expected the promise to be completed with 1, saw %v", value)
+	}
+}
+
+// Validate that once a promise has settled, it no longer refers to the
+// promises composed from it, nor to its callbacks.
+func TestReleaseReferences(test *testing.T) {
+	queue := NewSerialQueue()
+
+	defer queue.Close()
+
+	baseline := heapAlloc()
+
+	// The promises are settled on the queue, one after another, rather than
+	// each one by the last.
+	root := PromiseOn(queue)
+	last := Thenable(root)
+
+	for i := 0; i < CHAINLENGTH; i++ {
+		last = last.Then(func(value interface{}) interface{} {
+			return value.(int) + 1
+		})
+	}
+
+	root.Complete(0)
+
+	if value, _ := last.Get(); value != CHAINLENGTH {
+		test.Fatalf("Expected %d, saw %v", CHAINLENGTH, value)
+	}
+
+	if growth := int64(heapAlloc()) - int64(baseline); growth > HEAPGROWTH {
+		test.Fatalf("Expected the chain to be collected, but the heap grew by %d bytes", growth)
+	}
+
+	source := Promise()
+	large := composeLarge(source)
+
+	source.Complete(10)
+
+	if growth := int64(heapAlloc()) - int64(baseline); growth > HEAPGROWTH {
+		test.Fatalf("Expected the callback to be collected, but the heap grew by %d bytes", growth)
+	}
+
+	runtime.KeepAlive(root)
+	runtime.KeepAlive(last)
+	runtime.KeepAlive(large)
+}
+
+// The most stack any goroutine may use while settling a long chain of
+// promises, which is far less than recursing through every link would take.
+const CHAINSTACK = 16 << 20
+
+// Build a chain of promises, each link composed from the last, and then
+// settle its first promise, checking the outcome of its last.
+func settleChain(test *testing.T, link func(Thenable) Thenable, settle func(Completable), check func(Result)) {
+	defer debug.SetMaxStack(debug.SetMaxStack(CHAINSTACK))
+
+	root := Promise()
+	last := Thenable(root)
+
+	for i := 0; i < CHAINLENGTH; i++ {
+		last = link(last)
+	}
+
+	settle(root)
+
+	check(last.Result())
+}
+
+// Validate that settling a long chain of promises composed with Then() does
+// not recurse through every link.
+func TestDeepThen(test *testing.T) {
+	settleChain(test, func(last Thenable) Thenable {
+		return last.Then(func(value interface{}) interface{} {
+			return value.(int) + 1
+		})
+	}, func(root Completable) {
+		root.Complete(0)
+	}, func(result Result) {
+		if result.Value != CHAINLENGTH {
+			test.Fatalf("Expected %d, saw %v", CHAINLENGTH, result)
+		}
+	})
+}
+
+// Validate that settling a long chain of promises composed on Inline does not
+// recurse through every link, any more than it does without an executor.
+func TestDeepInline(test *testing.T) {
+	settleChain(test, func(last Thenable) Thenable {
+		return last.ThenOn(Inline, func(value interface{}) interface{} {
+			return value.(int) + 1
+		})
+	}, func(root Completable) {
+		root.Complete(0)
+	}, func(result Result) {
+		if result.Value != CHAINLENGTH {
+			test.Fatalf("Expected %d, saw %v", CHAINLENGTH, result)
+		}
+	})
+}
+
+// Validate that a rejection flows through a long chain of promises composed
+// with Catch() without recursing through every link.
+func TestDeepCatch(test *testing.T) {
+	var expected = errors.New("Expected error!")
+
+	caught := 0
+
+	settleChain(test, func(last Thenable) Thenable {
+		return last.Catch(func(error) {
+			caught++
+		})
+	}, func(root Completable) {
+		root.Reject(expected)
+	}, func(result Result) {
+		if result.Cause != expected || caught != CHAINLENGTH {
+			test.Fatalf("Expected %d handlers to see %v, saw %d, %v", CHAINLENGTH, expected, caught, result)
+		}
+	})
+}
+
+// Validate that settling a long chain of promises composed with Combine(),
+// whether the promises they create have settled or not, does not recurse
+// through every link.
+func TestDeepCombine(test *testing.T) {
+	settleChain(test, func(last Thenable) Thenable {
+		return last.Combine(func(value interface{}) Thenable {
+			return Completed(value.(int) + 1)
+		})
+	}, func(root Completable) {
+		root.Complete(0)
+	}, func(result Result) {
+		if result.Value != CHAINLENGTH {
+			test.Fatalf("Expected %d, saw %v", CHAINLENGTH, result)
+		}
+	})
+
+	// Each link creates a promise which depends on the next, which is only
+	// completed once the whole chain has been built.
+	pending := Promise()
+
+	settleChain(test, func(last Thenable) Thenable {
+		return last.Combine(func(value interface{}) Thenable {
+			return pending.Then(func(interface{}) interface{} {
+				return value.(int) + 1
+			})
+		})
+	}, func(root Completable) {
+		root.Complete(0)
+
+		pending.Complete(nil)
+	}, func(result Result) {
+		if result.Value != CHAINLENGTH {
+			test.Fatalf("Expected %d, saw %v", CHAINLENGTH, result)
+		}
+	})
+}
+
+// Validate that cancelling the first promise of a long chain cancels every
+// link without recursing through them.
+func TestDeepCancel(test *testing.T) {
+	settleChain(test, func(last Thenable) Thenable {
+		return last.Then(func(value interface{}) interface{} {
+			return value
+		})
+	}, func(root Completable) {
+		root.Cancel()
+	}, func(result Result) {
+		if result.State != CANCELLED {
+			test.Fatalf("Expected the chain to be cancelled, saw %v", result)
+		}
+	})
+}
+
+// A promise with a mutex, as CompletablePromise once was, for comparison. Only
+// what the benchmarks need is here, but it computes values in the same way.
+type lockedPromise struct {
+	state        uint32
+	value        interface{}
+	mutex        sync.Mutex
+	done         chan struct{}
+	compute      func(interface{}) (interface{}, error)
+	dependencies []*lockedPromise
+}
+
+func newLockedPromise(compute func(interface{}) (interface{}, error)) *lockedPromise {
+	return &lockedPromise{done: make(chan struct{}), compute: compute}
+}
+
+func (promise *lockedPromise) Then(compute func(interface{}) interface{}) *lockedPromise {
+	promise.mutex.Lock()
+
+	defer promise.mutex.Unlock()
+
+	dependency := newLockedPromise(infallible(compute))
+
+	if atomic.LoadUint32(&promise.state) == PENDING {
+		promise.dependencies = append(promise.dependencies, dependency)
+	} else {
+		dependency.Complete(promise.value)
+	}
+
+	return dependency
+}
+
+func (promise *lockedPromise) Complete(value interface{}) {
+	if promise.compute != nil {
+		value, _ = tryCompute(promise.compute, value)
+	}
+
+	promise.mutex.Lock()
+
+	promise.value = value
+
+	atomic.StoreUint32(&promise.state, FULFILLED)
+
+	dependencies := promise.dependencies
+
+	promise.mutex.Unlock()
+
+	close(promise.done)
+
+	for _, dependency := range dependencies {
+		dependency.Complete(value)
+	}
+}
+
+func (promise *lockedPromise) Get() (interface{}, error) {
+	<-promise.done
+
+	return promise.value, nil
+}
+
+// The number of promises composed from a single one when benchmarking fanout,
+// and of goroutines contending over a single one.
+const (
+	FANOUT     = 1000
+	CONTENDERS = 8
+)
+
+func identity(value interface{}) interface{} {
+	return value
+}
+
+func BenchmarkFanout(bench *testing.B) {
+	for i := 0; i < bench.N; i++ {
+		promise := Promise()
+
+		var last Thenable
+
+		for j := 0; j < FANOUT; j++ {
+			last = promise.Then(identity)
+		}
+
+		promise.Complete(i)
+
+		last.Get()
+	}
+}
+
+func BenchmarkFanoutLocked(bench *testing.B) {
+	for i := 0; i < bench.N; i++ {
+		promise := newLockedPromise(nil)
+
+		var last *lockedPromise
+
+		for j := 0; j < FANOUT; j++ {
+			last = promise.Then(identity)
+		}
+
+		promise.Complete(i)
+
+		last.Get()
+	}
+}
+
+// Have a number of goroutines compose promises from, and wait on, a promise
+// which is being completed by yet another.
+func benchmarkContended(bench *testing.B, create func() (then func(), get func(), complete func())) {
+	var group sync.WaitGroup
+
+	for i := 0; i < bench.N; i++ {
+		then, get, complete := create()
+
+		group.Add(CONTENDERS)
+
+		for j := 0; j < CONTENDERS; j++ {
+			go func() {
+				defer group.Done()
+
+				for k := 0; k < FANOUT/CONTENDERS; k++ {
+					then()
+				}
+
+				get()
+			}()
+		}
+
+		complete()
+
+		group.Wait()
+	}
+}
+
+func BenchmarkContended(bench *testing.B) {
+	benchmarkContended(bench, func() (func(), func(), func()) {
+		promise := Promise()
+
+		return func() {
+				promise.Then(identity)
+			}, func() {
+				promise.Get()
+			}, func() {
+				promise.Complete(10)
+			}
+	})
+}
+
+func BenchmarkContendedLocked(bench *testing.B) {
+	benchmarkContended(bench, func() (func(), func(), func()) {
+		promise := newLockedPromise(nil)
+
+		return func() {
+				promise.Then(identity)
+			}, func() {
+				promise.Get()
+			}, func() {
+				promise.Complete(10)
+			}
+	})
+}
@@ -1,5 +1,10 @@
 package promise
 
+import (
+	"context"
+	"time"
+)
+
 // A completed promise. as returned by the `promise.Completed()` method.
 type CompletedPromise struct {
 	value interface{}
@@ -25,22 +30,90 @@ func (promise *CompletedPromise) Rejected() bool {
 	return false
 }
 
+// Always false, a completed promise can not be cancelled.
+func (promise *CompletedPromise) Cancelled() bool {
+	return false
+}
+
 // Always returns the value that this promise was initialized with.
 func (promise *CompletedPromise) Get() (interface{}, error) {
 	return promise.value, nil
 }
 
+// Always returns the value that this promise was initialized with, which is
+// always settled.
+func (promise *CompletedPromise) TryGet() (interface{}, error, bool) {
+	return promise.value, nil, true
+}
+
+// Always the value that this promise was initialized with.
+func (promise *CompletedPromise) Result() Result {
+	return Result{Value: promise.value, State: FULFILLED}
+}
+
+// Always returns the value that this promise was initialized with, there is
+// never any need to wait.
+func (promise *CompletedPromise) GetContext(ctx context.Context) (interface{}, error) {
+	return promise.Get()
+}
+
+// Always returns the value that this promise was initialized with.
+func (promise *CompletedPromise) GetTimeout(timeout time.Duration) (interface{}, error) {
+	return promise.Get()
+}
+
 // Create a completed promise for the value of this promise with the compute
 // function applied.
 func (promise *CompletedPromise) Then(compute func(interface{}) interface{}) Thenable {
-	return Completed(compute(promise.value))
+	return promise.ThenTry(infallible(compute))
+}
+
+// Create a completed promise for the value of this promise with the compute
+// function applied, or a rejected promise if it fails.
+func (promise *CompletedPromise) ThenTry(compute func(interface{}) (interface{}, error)) Thenable {
+	return settled(tryCompute(compute, promise.value))
+}
+
+// Create a promise for the value of this promise with the compute function
+// applied on the given executor.
+func (promise *CompletedPromise) ThenOn(executor Executor, compute func(interface{}) interface{}) Thenable {
+	derived := completable(infallible(compute), nil)
+
+	derived.executor = executor
+
+	derived.settle(promise.value, nil)
+
+	return derived
 }
 
 // Create a promise from this value and another promise.
 func (promise *CompletedPromise) Combine(create func(interface{}) Thenable) Thenable {
-	return create(promise.value)
+	return tryCreate(create, promise.value)
 }
 
 func (promise *CompletedPromise) Catch(handle func(error)) Thenable {
 	return promise
 }
+
+// Run the hook immediately, and return this promise.
+func (promise *CompletedPromise) Finally(hook func()) Thenable {
+	if cause := tryFinally(hook); cause != nil {
+		return Rejected(cause)
+	}
+
+	return promise
+}
+
+// Create a promise which is completed with the same value once the duration
+// has elapsed.
+func (promise *CompletedPromise) Delay(duration time.Duration) Thenable {
+	return delay(promise, duration)
+}
+
+func (promise *CompletedPromise) Recover(handle func(error) (interface{}, error)) Thenable {
+	return promise
+}
+
+func (promise *CompletedPromise) RecoverWith(create func(error) Thenable) Thenable {
+	return promise
+}
@@ -0,0 +1,79 @@
+package promise
+
+import (
+	"sync"
+	"time"
+)
+
+// Create a promise which is completed with the given value once the duration
+// has elapsed. Cancelling the promise stops the timer.
+func Delay(duration time.Duration, value interface{}) Thenable {
+	delayed := completable(nil, nil)
+
+	timer := now().AfterFunc(duration, func() {
+		delayed.TryComplete(value)
+	})
+
+	delayed.OnCancel(func() {
+		timer.Stop()
+	})
+
+	return delayed
+}
+
+// Create a promise which is completed with the given time once it has come.
+// Cancelling the promise stops the timer.
+func After(when time.Time) Thenable {
+	return Delay(when.Sub(now().Now()), when)
+}
+
+// Create a promise which settles as the given thenable does, but only once the
+// duration has elapsed after the thenable settled. If the thenable is
+// cancelled, the promise is cancelled straight away. Cancelling the promise
+// stops the timer, if there is one, and stops observing the thenable.
+func delay(thenable Thenable, duration time.Duration) Thenable {
+	delayed := completable(nil, nil)
+
+	var mutex sync.Mutex
+	var timer Timer
+
+	stop := watch(thenable, func(result Result) {
+		if result.State == CANCELLED {
+			delayed.Cancel()
+
+			return
+		}
+
+		started := now().AfterFunc(duration, func() {
+			delayed.resolve(result.Value, result.Cause)
+		})
+
+		mutex.Lock()
+
+		timer = started
+
+		mutex.Unlock()
+
+		// The promise may have been cancelled before there was a timer to
+		// stop.
+		if delayed.Cancelled() {
+			started.Stop()
+		}
+	})
+
+	delayed.OnCancel(func() {
+		stop()
+
+		mutex.Lock()
+
+		started := timer
+
+		mutex.Unlock()
+
+		if started != nil {
+			started.Stop()
+		}
+	})
+
+	return delayed
+}
@@ -0,0 +1,122 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// Validate that delayed promises are completed once the time has come, and
+// that cancelling them stops their timers.
+func TestDelay(test *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+
+	defer SetClock(SetClock(clock))
+
+	delayed := Delay(time.Second, 10)
+	scheduled := After(clock.Now().Add(time.Minute))
+
+	clock.Advance(time.Second - 1)
+
+	if delayed.Resolved() {
+		test.Fatalf("Expected the promise not to be completed early")
+	}
+
+	clock.Advance(1)
+
+	if value, _, _ := delayed.TryGet(); value != 10 {
+		test.Fatalf("Expected 10, saw %v", value)
+	}
+
+	clock.Advance(time.Minute)
+
+	if value, _, _ := scheduled.TryGet(); value != time.Unix(60, 0) {
+		test.Fatalf("Expected the time it was scheduled for, saw %v", value)
+	}
+
+	expected := errors.New("Expected error!")
+	raced := Delay(time.Second, 10)
+
+	raced.(Completable).TryReject(expected)
+
+	clock.Advance(time.Second)
+
+	if _, err := raced.Get(); err != expected {
+		test.Fatalf("Expected %v, saw %v", expected, err)
+	}
+
+	cancelled := Delay(time.Second, 10)
+
+	cancelled.(Completable).Cancel()
+
+	if clock.Timers() != 0 {
+		test.Fatalf("Expected the timer to be stopped")
+	}
+}
+
+// Validate that the settlement of a promise can be postponed.
+func TestThenableDelay(test *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+
+	defer SetClock(SetClock(clock))
+
+	var expected = errors.New("Expected error!")
+
+	source := Promise()
+
+	delayed := []Thenable{
+		source.Delay(time.Second),
+		Completed(20).Delay(time.Second),
+		Rejected(expected).Delay(time.Second),
+	}
+
+	source.Complete(10)
+
+	for _, each := range delayed {
+		if _, _, settled := each.TryGet(); settled {
+			test.Fatalf("Expected the promise not to settle early")
+		}
+	}
+
+	clock.Advance(time.Second)
+
+	if value, _ := delayed[0].Get(); value != 10 {
+		test.Fatalf("Expected 10, saw %v", value)
+	}
+
+	if value, _ := delayed[1].Get(); value != 20 {
+		test.Fatalf("Expected 20, saw %v", value)
+	}
+
+	if _, err := delayed[2].Get(); err != expected {
+		test.Fatalf("Expected %v, saw %v", expected, err)
+	}
+
+	completed := Promise()
+	abandoned := completed.Delay(time.Second)
+
+	completed.Complete(10)
+
+	abandoned.(Completable).Cancel()
+
+	if clock.Timers() != 0 {
+		test.Fatalf("Expected the timer to be stopped")
+	}
+
+	cancelled := Promise()
+	delayedCancel := cancelled.Delay(time.Second)
+
+	cancelled.Cancel()
+
+	if !delayedCancel.Cancelled() {
+		test.Fatalf("Expected cancellation not to be delayed")
+	}
+
+	typed := CompletedOf(30).Delay(time.Second)
+
+	clock.Advance(time.Second)
+
+	if value, err := typed.Get(); value != 30 || err != nil {
+		test.Fatalf("Expected 30, saw %v, %v", value, err)
+	}
+}
@@ -0,0 +1,98 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// The cause returned by Get() for a promise which has been cancelled.
+var ErrCancelled = errors.New("promise: cancelled")
+
+// The cause of the rejection of a promise which was to take on the outcome of
+// another, such as one created by Combine(), when it was given nil instead.
+var ErrNilThenable = errors.New("promise: nil Thenable")
+
+// The cause of the rejection of a promise created by FromChan() for a channel
+// which was closed before a value was received from it.
+var ErrChannelClosed = errors.New("promise: channel closed without a value")
+
+// An error returned when waiting on a promise was abandoned because it took
+// too long.
+type TimeoutError struct {
+	Duration time.Duration
+}
+
+func (err *TimeoutError) Error() string {
+	return fmt.Sprintf("promise: timed out after %s", err.Duration)
+}
+
+// Always true, this satisfies the timeout convention of net.Error.
+func (err *TimeoutError) Timeout() bool {
+	return true
+}
+
+// A timeout is a kind of exceeded deadline, so errors.Is(err,
+// context.DeadlineExceeded) holds for a *TimeoutError.
+func (err *TimeoutError) Is(target error) bool {
+	return target == context.DeadlineExceeded
+}
+
+// An error describing the rejection of one of a number of promises, such as
+// those given to All(), by its position amongst them.
+type IndexedError struct {
+	Index int
+	Cause error
+}
+
+func (err *IndexedError) Error() string {
+	return fmt.Sprintf("promise %d: %s", err.Index, err.Cause)
+}
+
+func (err *IndexedError) Unwrap() error {
+	return err.Cause
+}
+
+// An error describing the rejection of several of a number of promises, such
+// as those given to Any(). Each of the errors records which of the promises
+// was rejected, and with what cause, in the order of the promises.
+type MultiError struct {
+	Errors []*IndexedError
+}
+
+func (err *MultiError) Error() string {
+	causes := make([]string, len(err.Errors))
+
+	for i, cause := range err.Errors {
+		causes[i] = cause.Error()
+	}
+
+	return fmt.Sprintf("promise: %d promises were rejected: %s",
+		len(err.Errors), strings.Join(causes, "; "))
+}
+
+// Allow errors.Is() and errors.As() to match any of the causes, or any of the
+// *IndexedErrors themselves.
+func (err *MultiError) Unwrap() []error {
+	errors := make([]error, len(err.Errors))
+
+	for i, cause := range err.Errors {
+		errors[i] = cause
+	}
+
+	return errors
+}
+
+// Return the cause of the rejection of the promise at the given index, or nil
+// if it was not rejected.
+func (err *MultiError) Cause(index int) error {
+	for _, cause := range err.Errors {
+		if cause.Index == index {
+			return cause.Cause
+		}
+	}
+
+	return nil
+}
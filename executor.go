@@ -0,0 +1,186 @@
+package promise
+
+import (
+	"context"
+	"sync"
+)
+
+// Something which runs tasks, such as the computations which produce the value
+// of a promise.
+// Implementations decide where and when each task runs, which may be on the
+// calling goroutine, on a goroutine of its own, or on one of a bounded number
+// of goroutines.
+type Executor interface {
+	// Run the task, at some point.
+	Execute(task func())
+}
+
+// An adapter which allows an ordinary function to be used as an Executor.
+type ExecutorFunc func(task func())
+
+func (execute ExecutorFunc) Execute(task func()) {
+	execute(task)
+}
+
+// An Executor which runs each task on a goroutine of its own.
+var Goroutine Executor = ExecutorFunc(func(task func()) {
+	go task()
+})
+
+// An Executor which runs each task immediately, on the goroutine which asked
+// for it to be run. Promises settle on Inline just as they do without an
+// executor, without handing it anything at all.
+var Inline Executor = inlineExecutor{}
+
+// The type of Inline, which unlike an ExecutorFunc can be compared, so that
+// promises can tell when they have been given it.
+type inlineExecutor struct{}
+
+func (inlineExecutor) Execute(task func()) {
+	task()
+}
+
+// An Executor which runs tasks on a fixed number of goroutines.
+// Tasks are queued until a worker is available to run them, in the order they
+// were given to Execute(), which never blocks. That way a task which itself
+// completes a promise whose computations run on the same pool can not
+// deadlock. The pool must be closed once it is no longer used, in order to
+// stop its goroutines.
+type WorkerPool struct {
+	mutex   sync.Mutex
+	ready   *sync.Cond
+	queue   []func()
+	closed  bool
+	workers sync.WaitGroup
+}
+
+// Create a pool of the given number of workers, which must be at least one.
+func NewWorkerPool(workers int) *WorkerPool {
+	if workers < 1 {
+		panic("NewWorkerPool() requires at least one worker")
+	}
+
+	pool := new(WorkerPool)
+
+	pool.ready = sync.NewCond(&pool.mutex)
+
+	pool.workers.Add(workers)
+
+	for i := 0; i < workers; i++ {
+		go pool.work()
+	}
+
+	return pool
+}
+
+// Create a pool of a single worker, which runs tasks one at a time in the
+// order they were given to Execute().
+func NewSerialQueue() *WorkerPool {
+	return NewWorkerPool(1)
+}
+
+func (pool *WorkerPool) work() {
+	defer pool.workers.Done()
+
+	for {
+		pool.mutex.Lock()
+
+		for len(pool.queue) == 0 && !pool.closed {
+			pool.ready.Wait()
+		}
+
+		if len(pool.queue) == 0 {
+			pool.mutex.Unlock()
+
+			return
+		}
+
+		task := pool.queue[0]
+
+		pool.queue[0] = nil
+		pool.queue = pool.queue[1:]
+
+		pool.mutex.Unlock()
+
+		task()
+	}
+}
+
+// Queue the task to run on the next available worker. It is an error to call
+// Execute() after Close().
+func (pool *WorkerPool) Execute(task func()) {
+	pool.mutex.Lock()
+
+	defer pool.mutex.Unlock()
+
+	if pool.closed {
+		panic("Execute() was called on a closed WorkerPool")
+	}
+
+	pool.queue = append(pool.queue, task)
+
+	pool.ready.Signal()
+}
+
+// Stop the workers once they have finished every task which was queued.
+func (pool *WorkerPool) Close() {
+	pool.mutex.Lock()
+
+	pool.closed = true
+
+	pool.ready.Broadcast()
+
+	pool.mutex.Unlock()
+
+	pool.workers.Wait()
+}
+
+// Run a task on a goroutine of its own, and return a promise for its result.
+// The promise is completed with the value the task returns, or rejected with
+// the error it returns. A panic within the task rejects the promise with a
+// *PanicError, unless panic recovery has been disabled.
+func Go(task func() (interface{}, error)) Thenable {
+	return GoOn(Goroutine, task)
+}
+
+// Run a task with the given executor, and return a promise for its result, as
+// Go() does. If the promise is cancelled before the executor gets around to
+// the task, it is not run at all.
+func GoOn(executor Executor, task func() (interface{}, error)) Thenable {
+	promise := completable(nil, nil)
+
+	executor.Execute(func() {
+		if promise.Cancelled() {
+			return
+		}
+
+		// The promise may have been settled some other way in the meantime,
+		// such as by a timeout, in which case the result is of no use.
+		promise.resolve(try(task))
+	})
+
+	return promise
+}
+
+// Run a task on a goroutine of its own, and return a promise for its result,
+// as Go() does. The task is given a context derived from ctx, which is
+// cancelled if the promise is cancelled, so that the task can stop early.
+func GoContext(ctx context.Context, task func(context.Context) (interface{}, error)) Thenable {
+	return GoContextOn(Goroutine, ctx, task)
+}
+
+// Run a task with the given executor, and return a promise for its result, as
+// GoContext() does.
+func GoContextOn(executor Executor, ctx context.Context, task func(context.Context) (interface{}, error)) Thenable {
+	ctx, cancel := context.WithCancel(ctx)
+
+	promise := GoOn(executor, func() (interface{}, error) {
+		defer cancel()
+
+		return task(ctx)
+	})
+
+	promise.(Completable).OnCancel(cancel)
+
+	return promise
+}
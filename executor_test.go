@@ -0,0 +1,247 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+// Validate that Go() produces a promise for the result of its task.
+func TestGo(test *testing.T) {
+	var expected = errors.New("Expected error!")
+
+	value, err := Go(func() (interface{}, error) {
+		return 10, nil
+	}).Get()
+
+	if value != 10 || err != nil {
+		test.Fatalf("Expected the value of the task, saw %v (%v)", value, err)
+	}
+
+	_, err = Go(func() (interface{}, error) {
+		return nil, expected
+	}).Get()
+
+	if err != expected {
+		test.Fatalf("Expected the error of the task, saw %v", err)
+	}
+
+	_, err = Go(func() (interface{}, error) {
+		panic("Expected panic!")
+	}).Get()
+
+	var panicked *PanicError
+
+	if !errors.As(err, &panicked) {
+		test.Fatalf("Expected a panicking task to reject, saw %v", err)
+	}
+
+	// The promise may be settled some other way before the task finishes,
+	// which must not upset the task's goroutine.
+	release := make(chan struct{})
+	finished := make(chan struct{})
+
+	raced := GoOn(ExecutorFunc(func(task func()) {
+		go func() {
+			task()
+
+			close(finished)
+		}()
+	}), func() (interface{}, error) {
+		<-release
+
+		return nil, expected
+	})
+
+	if !raced.(Completable).TryComplete(20) {
+		test.Fatalf("Expected TryComplete() to settle the promise first")
+	}
+
+	close(release)
+
+	<-finished
+
+	if value, _ := raced.Get(); value != 20 {
+		test.Fatalf("Expected 20, saw %v", value)
+	}
+}
+
+// Validate that cancelling the promise returned by GoContext() cancels the
+// context given to its task.
+func TestGoContext(test *testing.T) {
+	started := make(chan struct{})
+
+	promise := GoContext(context.Background(), func(ctx context.Context) (interface{}, error) {
+		close(started)
+
+		<-ctx.Done()
+
+		return nil, ctx.Err()
+	})
+
+	<-started
+
+	promise.(Completable).Cancel()
+
+	if _, err := promise.Get(); err != ErrCancelled {
+		test.Fatalf("Expected ErrCancelled, saw %v", err)
+	}
+}
+
+// Validate that tasks run on the executor they are given.
+func TestExecutors(test *testing.T) {
+	ran := false
+
+	promise := GoOn(Inline, func() (interface{}, error) {
+		ran = true
+
+		return nil, nil
+	})
+
+	if !ran || !promise.Resolved() {
+		test.Fatalf("Expected an Inline task to run immediately")
+	}
+
+	pool := NewWorkerPool(4)
+
+	var counter uint64
+
+	promises := make([]Thenable, 0, WAITERS)
+
+	for i := 0; i < WAITERS; i++ {
+		promises = append(promises, GoOn(pool, func() (interface{}, error) {
+			return atomic.AddUint64(&counter, 1), nil
+		}))
+	}
+
+	if _, err := All(promises...).Get(); err != nil {
+		test.Fatalf("Unexpected error: %s", err)
+	}
+
+	pool.Close()
+
+	if counter != WAITERS {
+		test.Fatalf("Expected %d tasks to run, saw %d", WAITERS, counter)
+	}
+}
+
+// Validate that the computations of promises composed from a promise with an
+// executor run on that executor, in order for a serial queue.
+func TestPromiseOn(test *testing.T) {
+	queue := NewSerialQueue()
+
+	defer queue.Close()
+
+	promise := PromiseOn(queue)
+
+	var order []int
+
+	derived := make([]Thenable, 0, WAITERS)
+
+	for i := 0; i < WAITERS; i++ {
+		i := i
+
+		derived = append(derived, promise.Then(func(value interface{}) interface{} {
+			order = append(order, i)
+
+			return value
+		}).Then(func(value interface{}) interface{} {
+			return value.(int) + i
+		}))
+	}
+
+	promise.Complete(1)
+
+	values, err := All(derived...).Get()
+
+	if err != nil {
+		test.Fatalf("Unexpected error: %s", err)
+	}
+
+	for i, value := range values.([]interface{}) {
+		if order[i] != i || value != i+1 {
+			test.Fatalf("Expected computations to run in order, saw %v", order)
+		}
+	}
+}
+
+// Validate that ThenOn() runs a computation on the given executor, even if the
+// promise has already settled.
+func TestThenOn(test *testing.T) {
+	pool := NewWorkerPool(1)
+
+	defer pool.Close()
+
+	blocked := make(chan struct{})
+
+	pool.Execute(func() {
+		<-blocked
+	})
+
+	promise := Promise()
+
+	derived := promise.ThenOn(pool, func(value interface{}) interface{} {
+		return value.(int) * 2
+	})
+
+	promise.Complete(10)
+
+	if derived.Resolved() {
+		test.Fatalf("Expected the computation to wait for the executor")
+	}
+
+	completed := Completed(20).ThenOn(pool, func(value interface{}) interface{} {
+		return value.(int) * 2
+	})
+
+	if completed.Resolved() {
+		test.Fatalf("Expected the computation to wait for the executor")
+	}
+
+	close(blocked)
+
+	if value, _ := derived.Get(); value != 20 {
+		test.Fatalf("Expected 10 * 2 to be 20, saw %v", value)
+	}
+
+	if value, _ := completed.Get(); value != 40 {
+		test.Fatalf("Expected 20 * 2 to be 40, saw %v", value)
+	}
+}
+
+// Validate that computations run in the order they were composed, whether
+// they were composed with Then(), or with ThenOn() on an executor which runs
+// them straight away, and that Inline is no different from having no
+// executor.
+func TestMixedExecutorOrder(test *testing.T) {
+	immediate := ExecutorFunc(func(task func()) {
+		task()
+	})
+
+	for _, executor := range []Executor{Inline, immediate} {
+		var order []string
+
+		record := func(name string) func(interface{}) interface{} {
+			return func(value interface{}) interface{} {
+				order = append(order, name)
+
+				return value
+			}
+		}
+
+		promise := Promise()
+
+		first := promise.Then(record("a"))
+		second := promise.ThenOn(executor, record("b"))
+		third := promise.Then(record("c"))
+
+		promise.Complete(1)
+
+		All(first, second, third).Get()
+
+		if len(order) != 3 || order[0] != "a" || order[1] != "b" || order[2] != "c" {
+			test.Fatalf("Expected a, b, c, saw %v", order)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package promise
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// A Clock whose time only passes when it is advanced, for testing code which
+// waits for time to pass without having to wait. Install it with SetClock().
+// Timers run on the goroutine which advances the clock, in the order they come
+// due.
+type FakeClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock *FakeClock
+	when  time.Time
+	task  func()
+}
+
+// Create a fake clock, starting at the given time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Return the time the clock has been advanced to.
+func (clock *FakeClock) Now() time.Time {
+	clock.mutex.Lock()
+
+	defer clock.mutex.Unlock()
+
+	return clock.now
+}
+
+// Run the task once the clock has been advanced by the duration.
+func (clock *FakeClock) AfterFunc(duration time.Duration, task func()) Timer {
+	clock.mutex.Lock()
+
+	defer clock.mutex.Unlock()
+
+	timer := &fakeTimer{clock: clock, when: clock.now.Add(duration), task: task}
+
+	clock.timers = append(clock.timers, timer)
+
+	return timer
+}
+
+func (timer *fakeTimer) Stop() bool {
+	timer.clock.mutex.Lock()
+
+	defer timer.clock.mutex.Unlock()
+
+	for i, each := range timer.clock.timers {
+		if each == timer {
+			timer.clock.timers = append(timer.clock.timers[:i], timer.clock.timers[i+1:]...)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// Return the number of timers which have neither fired nor been stopped.
+func (clock *FakeClock) Timers() int {
+	clock.mutex.Lock()
+
+	defer clock.mutex.Unlock()
+
+	return len(clock.timers)
+}
+
+// Advance the time, running every timer which comes due, in order, including
+// those started by other timers along the way. Timers which are due at the
+// same time run in the order they were started.
+func (clock *FakeClock) Advance(duration time.Duration) {
+	clock.mutex.Lock()
+
+	until := clock.now.Add(duration)
+
+	for {
+		sort.SliceStable(clock.timers, func(i, j int) bool {
+			return clock.timers[i].when.Before(clock.timers[j].when)
+		})
+
+		if len(clock.timers) == 0 || clock.timers[0].when.After(until) {
+			break
+		}
+
+		timer := clock.timers[0]
+
+		clock.timers = clock.timers[1:]
+
+		if timer.when.After(clock.now) {
+			clock.now = timer.when
+		}
+
+		// The task may well start or stop timers of its own.
+		clock.mutex.Unlock()
+
+		timer.task()
+
+		clock.mutex.Lock()
+	}
+
+	clock.now = until
+
+	clock.mutex.Unlock()
+}
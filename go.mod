@@ -0,0 +1,3 @@
+module github.com/quantcast/promise
+
+go 1.24
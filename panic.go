@@ -0,0 +1,121 @@
+package promise
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+// An error describing a panic which occurred within a callback, such as those
+// given to Then(), Combine() or Catch(). The promise which the callback was
+// computing is rejected with it.
+type PanicError struct {
+	// The value which was recovered from the panic.
+	Value interface{}
+
+	// The stack trace of the goroutine which panicked, as of the panic.
+	Stack []byte
+}
+
+func (err *PanicError) Error() string {
+	return fmt.Sprintf("promise: panic in callback: %v", err.Value)
+}
+
+// A panic with an error, for instance a runtime.Error, unwraps to that error.
+func (err *PanicError) Unwrap() error {
+	cause, _ := err.Value.(error)
+
+	return cause
+}
+
+// Non-zero when panics within callbacks are to be recovered.
+var recovering uint32 = 1
+
+// Set whether or not panics within callbacks are recovered and turned into
+// rejections, returning the previous setting. Recovery is enabled by default,
+// and disabling it lets a panic crash the goroutine which ran the callback,
+// as Go ordinarily would, which can be useful in tests. The previous setting
+// is returned so that it may be restored with:
+//
+//	defer promise.SetPanicRecovery(promise.SetPanicRecovery(false))
+func SetPanicRecovery(enabled bool) bool {
+	var value uint32
+
+	if enabled {
+		value = 1
+	}
+
+	return atomic.SwapUint32(&recovering, value) != 0
+}
+
+// Run a callback, turning a panic within it into a *PanicError if recovery is
+// enabled.
+func try(callback func() (interface{}, error)) (value interface{}, cause error) {
+	if atomic.LoadUint32(&recovering) == 0 {
+		return callback()
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			value, cause = nil, &PanicError{Value: recovered, Stack: debug.Stack()}
+		}
+	}()
+
+	return callback()
+}
+
+// Run a computation on a value, as try() does.
+func tryCompute(compute func(interface{}) (interface{}, error), value interface{}) (interface{}, error) {
+	return try(func() (interface{}, error) {
+		return compute(value)
+	})
+}
+
+// Run an error handler on a cause, as try() does.
+func tryRecover(handle func(error) (interface{}, error), cause error) (interface{}, error) {
+	return try(func() (interface{}, error) {
+		return handle(cause)
+	})
+}
+
+// Run a hook given to Finally(), as try() does, returning the error with which
+// the promise it is computing should be rejected if it panicked.
+func tryFinally(hook func()) error {
+	_, cause := try(func() (interface{}, error) {
+		hook()
+
+		return nil, nil
+	})
+
+	return cause
+}
+
+// Create a promise with a combinator, as try() does, so that a panic rejects
+// the promise which would have been created. So does creating nil, which
+// would otherwise panic wherever the promise was next used.
+func tryCreate(create func(interface{}) Thenable, value interface{}) Thenable {
+	var created Thenable
+
+	_, err := try(func() (interface{}, error) {
+		created = create(value)
+
+		return nil, nil
+	})
+
+	if err != nil {
+		return Rejected(err)
+	}
+
+	if created == nil {
+		return Rejected(ErrNilThenable)
+	}
+
+	return created
+}
+
+// Create a promise to replace a rejected one, as tryCreate() does.
+func tryReplace(create func(error) Thenable, cause error) Thenable {
+	return tryCreate(func(interface{}) Thenable {
+		return create(cause)
+	}, nil)
+}
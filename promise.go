@@ -7,6 +7,11 @@
 // monadic combinator (`Combine()`) methods.
 package promise
 
+import (
+	"context"
+	"time"
+)
+
 // A computation which can be composed with Then().
 // Types which implement this interface can be composed with the Then() method,
 // they have an indicator of their status, Resolved(), which determines whether
@@ -21,11 +26,24 @@ type Thenable interface {
 	// point.
 	Rejected() bool
 
+	// Cancelled determines whether or not a promise has been cancelled. A
+	// cancelled promise is neither resolved nor rejected.
+	Cancelled() bool
+
 	// Create a new Thenable which is the result of this computation and the
 	// transformation function herein.
 	// TODO This needs a way to actually give you a new promise.
 	Then(func(interface{}) interface{}) Thenable
 
+	// Create a new Thenable which is the result of this computation and the
+	// transformation function herein, or which is rejected with the error the
+	// transformation returns, if it returns one.
+	ThenTry(func(interface{}) (interface{}, error)) Thenable
+
+	// Create a new Thenable which is the result of this computation and the
+	// transformation function herein, which runs on the given Executor.
+	ThenOn(Executor, func(interface{}) interface{}) Thenable
+
 	// Combine this thenable with another thenable.
 	// Given a function which accepts the value from this thenable, return a
 	// new Thenable that is resolved with the Thenable that is the result of
@@ -35,10 +53,46 @@ type Thenable interface {
 	// Handle an error which has occurred during processing.
 	Catch(func(error)) Thenable
 
+	// Handle an error which has occurred during processing, by either
+	// returning a value with which to complete the new Thenable, or an error
+	// with which to reject it.
+	Recover(func(error) (interface{}, error)) Thenable
+
+	// Handle an error which has occurred during processing, by returning a
+	// Thenable which takes the place of this one.
+	RecoverWith(func(error) Thenable) Thenable
+
+	// Run a hook once this thenable settles, however it settles, and return a
+	// new Thenable which settles in the same way afterward.
+	Finally(func()) Thenable
+
+	// Return a new Thenable which settles as this one does, but only once the
+	// duration has elapsed after this one settled.
+	Delay(time.Duration) Thenable
+
 	// Return the value of this Thenable, or the error which occurred.
 	// Implementations which are impure must block until the promise is either
 	// resolved or rejected.
 	Get() (interface{}, error)
+
+	// Return the value of this Thenable, or the error which occurred, as
+	// Get() does, without ever blocking. If the promise has not settled yet,
+	// settled is false, and neither the value nor the error mean anything.
+	TryGet() (value interface{}, err error, settled bool)
+
+	// Return the outcome of this Thenable. Implementations which are impure
+	// must block until the promise has settled, as they do for Get().
+	Result() Result
+
+	// Return the value of this Thenable, or the error which occurred, as
+	// Get() does. Implementations which block must stop waiting when the
+	// context is done, and return the context's error.
+	GetContext(context.Context) (interface{}, error)
+
+	// Return the value of this Thenable, or the error which occurred, as
+	// Get() does. Implementations which block must stop waiting after the
+	// timeout, and return a *TimeoutError.
+	GetTimeout(time.Duration) (interface{}, error)
 }
 
 // A promise which can be completed.
@@ -50,44 +104,36 @@ type Completable interface {
 
 	// Reject this promise and all of its derivatives.
 	Reject(error)
+
+	// Complete a promise, unless it has already settled, returning whether or
+	// not this call settled it.
+	TryComplete(interface{}) bool
+
+	// Reject a promise, unless it has already settled, returning whether or
+	// not this call settled it.
+	TryReject(error) bool
+
+	// Settle a promise as the given thenable settles.
+	CompleteWith(Thenable)
+
+	// Cancel this promise and all of its derivatives. Unlike Reject(), it is
+	// not an error to cancel a promise which has already settled.
+	Cancel()
+
+	// Register a hook to run when this promise is cancelled.
+	OnCancel(func())
 }
 
-// Combine the given promises as a single promise which produces a slice of
-// values. Given an arbitrarily long list of promises (as variadic arguments)
-// combine all of the promises to a single promise which transforms all of the
-// results of the promises into a slice (as []interface{}).
-func All(thenables ...Thenable) Thenable {
-	var cursor Thenable
-
-	for _, each := range thenables {
-		// For the first thenable, transform it's value into an array of
-		// values.
-		if cursor == nil {
-			cursor = each.Then(func(value interface{}) interface{} {
-				return []interface{}{value}
-			})
-
-			continue
-		}
-
-		// Afterward, we combine that promise and the next one, but in order to
-		// maintain referential integrity given there's no guarantees about
-		// when the combine function runs, we have to close over the value so
-		// as to copy the reference.
-		cursor = func(promise Thenable) Thenable {
-			return cursor.Combine(func(left interface{}) Thenable {
-				values, ok := left.([]interface{})
-
-				if !ok {
-					panic("Expected an array in combiner")
-				}
-
-				return promise.Then(func(right interface{}) interface{} {
-					return append(values, right)
-				})
-			})
-		}(each)
-	}
-
-	return cursor
+// Compose a thenable into another one which takes the place of a rejection
+// with the thenable that create returns, and otherwise passes its value on.
+// This is the same as chaining Then(), Recover() and Combine(), so it works for
+// any Thenable.
+func recoverWith(thenable Thenable, create func(error) Thenable) Thenable {
+	return thenable.Then(func(value interface{}) interface{} {
+		return Completed(value)
+	}).Recover(func(cause error) (interface{}, error) {
+		return tryReplace(create, cause), nil
+	}).Combine(func(replacement interface{}) Thenable {
+		return replacement.(Thenable)
+	})
 }
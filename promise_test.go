@@ -1,8 +1,10 @@
 package promise
 
 import (
+	"context"
 	"errors"
 	"runtime"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -12,6 +14,25 @@ func init() {
 	runtime.GOMAXPROCS(runtime.NumCPU())
 }
 
+// Crash the test binary if the calling test hasn't returned within a few
+// seconds, which is the only way to report a deadlock. Call the returned
+// function once the test is done, usually with defer.
+func watchdog(name string) func() {
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-time.After(5 * time.Second):
+			panic(name + " appears to have deadlocked")
+		case <-done:
+		}
+	}()
+
+	return func() {
+		close(done)
+	}
+}
+
 // Ensure that the basic properties of a promise holds true if the value is
 // already resolved.
 func TestCompletedPromise(test *testing.T) {
@@ -54,15 +75,12 @@ func TestCompletedPromise(test *testing.T) {
 // Validate that a promise that depends on a promise may use that promise when
 // it is completed.
 func TestReentrantComplete(test *testing.T) {
-	go func() {
-		time.Sleep(5 * time.Second)
-		test.Fatalf("TestReentrantComplete appears to have deadlocked")
-	}()
+	defer watchdog("TestReentrantComplete")()
 
 	a := Promise()
 	b := Promise()
 
-	b.Combine(func(value interface{}) Thenable {
+	sum := b.Combine(func(value interface{}) Thenable {
 		bValue, _ := value.(int)
 
 		return a.Then(func(value interface{}) interface{} {
@@ -70,7 +88,9 @@ func TestReentrantComplete(test *testing.T) {
 
 			return bValue + aValue
 		})
-	}).Then(func(value interface{}) interface{} {
+	})
+
+	sum.Then(func(value interface{}) interface{} {
 		sumValue, _ := value.(int)
 
 		if sumValue != 5 {
@@ -90,6 +110,161 @@ func TestReentrantComplete(test *testing.T) {
 	})
 
 	a.Complete(2)
+
+	if value, _ := sum.Get(); value != 5 {
+		test.Fatalf("Expected 5, saw %v", value)
+	}
+}
+
+// Validate that a computation may compose promises from both the promise it
+// depends on, which has settled, and the promise it is computing the value
+// of, which has not.
+func TestReentrantThen(test *testing.T) {
+	defer watchdog("TestReentrantThen")()
+
+	promise := Promise()
+
+	var derived, settled, pending Thenable
+
+	derived = promise.Then(func(value interface{}) interface{} {
+		settled = promise.Then(func(value interface{}) interface{} {
+			return value.(int) * 2
+		})
+
+		pending = derived.Then(func(value interface{}) interface{} {
+			return value.(int) * 3
+		})
+
+		if _, _, ok := derived.TryGet(); ok {
+			test.Fatalf("Expected the promise being computed not to have settled")
+		}
+
+		return value.(int) + 1
+	})
+
+	promise.Complete(10)
+
+	if value, _ := settled.Get(); value != 20 {
+		test.Fatalf("Expected 20, saw %v", value)
+	}
+
+	if value, _ := pending.Get(); value != 33 {
+		test.Fatalf("Expected 33, saw %v", value)
+	}
+}
+
+// Validate that an error handler may compose promises from, and wait on, the
+// promise it handles the rejection of, and the promise it is handling it for.
+func TestReentrantCatch(test *testing.T) {
+	defer watchdog("TestReentrantCatch")()
+
+	var expected = errors.New("Expected error!")
+
+	promise := Promise()
+
+	var caught, again, after Thenable
+
+	caught = promise.Catch(func(cause error) {
+		if _, err := promise.Get(); err != expected {
+			test.Fatalf("Expected %v, saw %v", expected, err)
+		}
+
+		again = promise.Catch(func(error) {})
+		after = caught.Recover(func(cause error) (interface{}, error) {
+			return 10, nil
+		})
+	})
+
+	promise.Reject(expected)
+
+	if _, err := again.Get(); err != expected {
+		test.Fatalf("Expected %v, saw %v", expected, err)
+	}
+
+	if value, _ := after.Get(); value != 10 {
+		test.Fatalf("Expected 10, saw %v", value)
+	}
+}
+
+// Validate that the function passed to Combine() may compose promises from
+// the promise it depends on, and from the promise which adopts the outcome of
+// the one it creates.
+func TestReentrantCombine(test *testing.T) {
+	defer watchdog("TestReentrantCombine")()
+
+	promise := Promise()
+
+	var combined, nested, adopted Thenable
+
+	combined = promise.Combine(func(value interface{}) Thenable {
+		nested = promise.Combine(func(value interface{}) Thenable {
+			return Completed(value.(int) * 2)
+		})
+
+		adopted = combined.Then(func(value interface{}) interface{} {
+			return value.(int) * 3
+		})
+
+		return nested.Then(func(value interface{}) interface{} {
+			return value.(int) + 1
+		})
+	})
+
+	promise.Complete(10)
+
+	if value, _ := combined.Get(); value != 21 {
+		test.Fatalf("Expected 21, saw %v", value)
+	}
+
+	if value, _ := adopted.Get(); value != 63 {
+		test.Fatalf("Expected 63, saw %v", value)
+	}
+}
+
+// Validate that a slow computation holds up nobody using the promises but
+// the goroutine running it.
+func TestSlowComputation(test *testing.T) {
+	defer watchdog("TestSlowComputation")()
+
+	promise := Promise()
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	derived := promise.Then(func(value interface{}) interface{} {
+		close(started)
+
+		<-release
+
+		return value
+	})
+
+	go promise.Complete(10)
+
+	<-started
+
+	if value, _ := promise.Get(); value != 10 {
+		test.Fatalf("Expected 10, saw %v", value)
+	}
+
+	promise.Then(func(value interface{}) interface{} {
+		return value
+	})
+
+	composed := derived.Then(func(value interface{}) interface{} {
+		return value
+	})
+
+	derived.(Completable).OnCancel(func() {})
+
+	if _, _, settled := derived.TryGet(); settled {
+		test.Fatalf("Expected the promise being computed not to have settled")
+	}
+
+	close(release)
+
+	if value, _ := composed.Get(); value != 10 {
+		test.Fatalf("Expected 10, saw %v", value)
+	}
 }
 
 // Ensure that the basic functions of the Promise API work for values that are
@@ -334,3 +509,800 @@ func TestRejected(test *testing.T) {
 		test.Fatalf("Rejected() did not invoke the onreject callback")
 	}
 }
+
+// Validate that waiting on a promise can be abandoned with a context.
+func TestGetContext(test *testing.T) {
+	promise := Promise()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go cancel()
+
+	_, err := promise.GetContext(ctx)
+
+	if err != context.Canceled {
+		test.Fatalf("Expected context.Canceled, saw %v", err)
+	}
+
+	promise.Complete(10)
+
+	// The promise is preferred over a context which is already done.
+	value, err := promise.GetContext(ctx)
+
+	if value != 10 || err != nil {
+		test.Fatalf("Expected the value of a completed promise, saw %v (%v)",
+			value, err)
+	}
+
+	value, _ = Completed(20).GetContext(ctx)
+
+	if value != 20 {
+		test.Fatalf("Expected the value of a Completed() promise, saw %v", value)
+	}
+}
+
+// Validate that waiting on a promise can be abandoned after a timeout.
+func TestGetTimeout(test *testing.T) {
+	promise := Promise()
+
+	_, err := promise.GetTimeout(time.Millisecond)
+
+	var timeout *TimeoutError
+
+	if !errors.As(err, &timeout) || timeout.Duration != time.Millisecond {
+		test.Fatalf("Expected a *TimeoutError, saw %v", err)
+	}
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		test.Fatalf("Expected a timeout to be a context.DeadlineExceeded")
+	}
+
+	var expected = errors.New("Expected error!")
+
+	go promise.Reject(expected)
+
+	_, err = promise.GetTimeout(time.Minute)
+
+	if err != expected {
+		test.Fatalf("Expected the cause of the rejection, saw %v", err)
+	}
+}
+
+// Validate that cancellation flows down to derived promises and up to the
+// producer, and that cancelled promises can not otherwise settle.
+func TestCancel(test *testing.T) {
+	promise := Promise()
+
+	producerStopped := false
+
+	promise.OnCancel(func() {
+		producerStopped = true
+	})
+
+	derived := promise.Then(func(value interface{}) interface{} {
+		test.Fatalf("Then() must not run for a cancelled promise")
+
+		return value
+	})
+
+	caught := derived.Catch(func(err error) {
+		test.Fatalf("Catch() must not run for a cancelled promise")
+	})
+
+	promise.Cancel()
+
+	if !producerStopped {
+		test.Fatalf("Expected the OnCancel() hook to run")
+	}
+
+	if !derived.Cancelled() || !caught.Cancelled() {
+		test.Fatalf("Expected cancellation to flow to derived promises")
+	}
+
+	// Neither of these may panic, the producer may not know it was cancelled.
+	promise.Complete(10)
+	promise.Reject(errors.New("Unexpected error!"))
+	promise.Cancel()
+
+	if _, err := caught.Get(); err != ErrCancelled {
+		test.Fatalf("Expected ErrCancelled from Get(), saw %v", err)
+	}
+
+	if !promise.Then(nil).Cancelled() {
+		test.Fatalf("Expected Then() on a cancelled promise to be cancelled")
+	}
+
+	completed := Promise()
+
+	completed.Complete(10)
+	completed.Cancel()
+
+	if completed.Cancelled() || !completed.Resolved() {
+		test.Fatalf("Expected Cancel() on a completed promise to do nothing")
+	}
+}
+
+// Validate that cancelling a combined promise cancels the promise which it was
+// combined with.
+func TestCancelCombine(test *testing.T) {
+	promise := Promise()
+	inner := Promise()
+
+	combined := promise.Combine(func(value interface{}) Thenable {
+		return inner
+	})
+
+	promise.Complete(10)
+	combined.(Completable).Cancel()
+
+	if !inner.Cancelled() {
+		test.Fatalf("Expected the inner promise to be cancelled")
+	}
+
+	promise = Promise()
+	inner = Promise()
+
+	combined = promise.Combine(func(value interface{}) Thenable {
+		return inner
+	})
+
+	promise.Complete(10)
+	inner.Cancel()
+
+	if !combined.Cancelled() {
+		test.Fatalf("Expected the combined promise to be cancelled")
+	}
+
+	promise = Promise()
+
+	combined = promise.Combine(func(value interface{}) Thenable {
+		test.Fatalf("Combine() must not run for a cancelled promise")
+
+		return nil
+	})
+
+	promise.Cancel()
+
+	if !combined.Cancelled() {
+		test.Fatalf("Expected cancellation to flow to the combined promise")
+	}
+}
+
+// Validate that a computation which fails rejects the derived promise, and
+// everything derived from that.
+func TestThenTry(test *testing.T) {
+	var expected = errors.New("Expected error!")
+
+	fail := func(value interface{}) (interface{}, error) {
+		return nil, expected
+	}
+
+	promise := Promise()
+
+	failed := promise.ThenTry(fail)
+
+	derived := failed.Then(func(value interface{}) interface{} {
+		test.Fatalf("Then() must not run after a failed computation")
+
+		return value
+	})
+
+	succeeded := promise.ThenTry(func(value interface{}) (interface{}, error) {
+		return value.(int) + 1, nil
+	})
+
+	promise.Complete(1)
+
+	if _, err := derived.Get(); err != expected || !failed.Rejected() {
+		test.Fatalf("Expected the failed computation to reject, saw %v", err)
+	}
+
+	if value, _ := succeeded.Get(); value != 2 {
+		test.Fatalf("Expected 1 + 1 to be 2, saw %v", value)
+	}
+
+	if _, err := promise.ThenTry(fail).Get(); err != expected {
+		test.Fatalf("Expected ThenTry() on a completed promise to reject")
+	}
+
+	if _, err := Completed(1).ThenTry(fail).Get(); err != expected {
+		test.Fatalf("Expected ThenTry() on a Completed() promise to reject")
+	}
+
+	if _, err := Rejected(expected).ThenTry(nil).Get(); err != expected {
+		test.Fatalf("Expected ThenTry() on a Rejected() promise to pass on the cause")
+	}
+}
+
+// Validate that a panic within a callback rejects the derived promise rather
+// than crashing the goroutine which completed its dependency.
+func TestPanicRecovery(test *testing.T) {
+	var expected = errors.New("Expected error!")
+
+	explode := func(value interface{}) interface{} {
+		panic(expected)
+	}
+
+	promise := Promise()
+
+	exploded := promise.Then(explode)
+
+	caught := promise.Then(func(value interface{}) interface{} {
+		return value
+	}).Catch(func(err error) {
+		test.Fatalf("Catch() must not run for a completed promise")
+	})
+
+	combined := promise.Combine(func(value interface{}) Thenable {
+		panic("Expected panic!")
+	})
+
+	promise.Complete(10)
+
+	_, err := exploded.Get()
+
+	var panicked *PanicError
+
+	if !errors.As(err, &panicked) || len(panicked.Stack) == 0 {
+		test.Fatalf("Expected a *PanicError with a stack trace, saw %v", err)
+	}
+
+	if !errors.Is(err, expected) {
+		test.Fatalf("Expected a *PanicError to unwrap to the panic's error")
+	}
+
+	if value, _ := caught.Get(); value != 10 {
+		test.Fatalf("Expected other dependencies to complete, saw %v", value)
+	}
+
+	if _, err = combined.Get(); !errors.As(err, &panicked) {
+		test.Fatalf("Expected Combine() to reject with a *PanicError, saw %v", err)
+	}
+
+	if _, err = Completed(10).Then(explode).Get(); !errors.As(err, &panicked) {
+		test.Fatalf("Expected Then() on a Completed() promise to reject")
+	}
+
+	rejected := Promise()
+
+	handled := rejected.Catch(func(err error) {
+		panic("Expected panic!")
+	})
+
+	rejected.Reject(expected)
+
+	if _, err = handled.Get(); !errors.As(err, &panicked) {
+		test.Fatalf("Expected a panicking Catch() to reject with a *PanicError")
+	}
+
+	// A combinator which returns nil rejects the promise it was to create,
+	// rather than panicking wherever that promise is used.
+	nothing := func(interface{}) Thenable {
+		return nil
+	}
+
+	pending := Promise()
+	created := pending.Combine(nothing)
+
+	pending.Complete(10)
+
+	if _, err = created.Get(); err != ErrNilThenable {
+		test.Fatalf("Expected ErrNilThenable, saw %v", err)
+	}
+
+	for _, settled := range []Thenable{Completed(10), pending} {
+		if _, err = settled.Combine(nothing).Get(); err != ErrNilThenable {
+			test.Fatalf("Expected ErrNilThenable, saw %v", err)
+		}
+	}
+
+	adopting := Promise()
+
+	adopting.CompleteWith(nil)
+
+	if _, err = adopting.Get(); err != ErrNilThenable {
+		test.Fatalf("Expected ErrNilThenable, saw %v", err)
+	}
+}
+
+// Validate that panic recovery can be disabled.
+func TestPanicRecoveryDisabled(test *testing.T) {
+	defer SetPanicRecovery(SetPanicRecovery(false))
+
+	defer func() {
+		if recover() == nil {
+			test.Fatalf("Expected the panic to crash the completing goroutine")
+		}
+	}()
+
+	promise := Promise()
+
+	promise.Then(func(value interface{}) interface{} {
+		panic("Expected panic!")
+	})
+
+	promise.Complete(10)
+}
+
+// Validate that a handler given to Recover() can turn a rejection back into a
+// value, or into another error.
+func TestRecover(test *testing.T) {
+	var expected = errors.New("Expected error!")
+
+	fallback := func(err error) (interface{}, error) {
+		if err != expected {
+			test.Fatalf("Expected the cause of the rejection, saw %v", err)
+		}
+
+		return 10, nil
+	}
+
+	promise := Promise()
+
+	recovered := promise.Recover(fallback).Then(func(value interface{}) interface{} {
+		return value.(int) + 1
+	})
+
+	replaced := promise.Recover(func(err error) (interface{}, error) {
+		return nil, errors.New("Replaced error!")
+	})
+
+	combined := promise.Combine(func(value interface{}) Thenable {
+		return Completed(value)
+	}).Recover(fallback)
+
+	promise.Reject(expected)
+
+	if value, err := recovered.Get(); value != 11 || err != nil {
+		test.Fatalf("Expected the recovered value, saw %v (%v)", value, err)
+	}
+
+	if _, err := replaced.Get(); err == nil || err == expected {
+		test.Fatalf("Expected the error returned by Recover(), saw %v", err)
+	}
+
+	if value, _ := combined.Get(); value != 10 {
+		test.Fatalf("Expected a combined promise to recover, saw %v", value)
+	}
+
+	if value, _ := promise.Recover(fallback).Get(); value != 10 {
+		test.Fatalf("Expected a rejected promise to recover, saw %v", value)
+	}
+
+	if value, _ := Rejected(expected).Recover(fallback).Get(); value != 10 {
+		test.Fatalf("Expected a Rejected() promise to recover, saw %v", value)
+	}
+
+	if value, _ := Completed(20).Recover(fallback).Get(); value != 20 {
+		test.Fatalf("Expected a Completed() promise to pass on its value")
+	}
+}
+
+// Validate that a handler given to RecoverWith() can replace a rejection with
+// another promise.
+func TestRecoverWith(test *testing.T) {
+	var expected = errors.New("Expected error!")
+
+	promise := Promise()
+	replacement := Promise()
+
+	recovered := promise.RecoverWith(func(err error) Thenable {
+		return replacement
+	})
+
+	promise.Reject(expected)
+
+	if recovered.Resolved() {
+		test.Fatalf("Expected to wait on the replacement promise")
+	}
+
+	replacement.Complete(10)
+
+	if value, _ := recovered.Get(); value != 10 {
+		test.Fatalf("Expected the value of the replacement, saw %v", value)
+	}
+
+	value, _ := Rejected(expected).RecoverWith(func(err error) Thenable {
+		return Completed(20)
+	}).Get()
+
+	if value != 20 {
+		test.Fatalf("Expected a Rejected() promise to be replaced, saw %v", value)
+	}
+
+	completed := Promise()
+
+	passed := completed.RecoverWith(func(err error) Thenable {
+		test.Fatalf("RecoverWith() must not run for a completed promise")
+
+		return nil
+	})
+
+	completed.Complete(30)
+
+	if value, _ := passed.Get(); value != 30 {
+		test.Fatalf("Expected the value to be passed on, saw %v", value)
+	}
+}
+
+// Validate that Finally() hooks run exactly once however a promise settles,
+// and pass on the outcome unchanged.
+func TestFinally(test *testing.T) {
+	var expected = errors.New("Expected error!")
+
+	var order []string
+
+	counted := func(name string) func() {
+		return func() {
+			order = append(order, name)
+		}
+	}
+
+	completed := Promise()
+	rejected := Promise()
+	cancelled := Promise()
+
+	completed.Then(func(value interface{}) interface{} {
+		order = append(order, "before")
+
+		return value
+	})
+
+	afterCompleted := completed.Finally(counted("completed"))
+
+	completed.Then(func(value interface{}) interface{} {
+		order = append(order, "after")
+
+		return value
+	})
+
+	afterRejected := rejected.Finally(counted("rejected"))
+	afterCancelled := cancelled.Finally(counted("cancelled"))
+
+	completed.Complete(10)
+	rejected.Reject(expected)
+	cancelled.Cancel()
+
+	expectedOrder := []string{"before", "completed", "after", "rejected", "cancelled"}
+
+	if len(order) != len(expectedOrder) {
+		test.Fatalf("Expected hooks %v, saw %v", expectedOrder, order)
+	}
+
+	for i, name := range expectedOrder {
+		if order[i] != name {
+			test.Fatalf("Expected hooks %v, saw %v", expectedOrder, order)
+		}
+	}
+
+	if value, _ := afterCompleted.Get(); value != 10 {
+		test.Fatalf("Expected Finally() to pass on the value, saw %v", value)
+	}
+
+	if _, err := afterRejected.Get(); err != expected {
+		test.Fatalf("Expected Finally() to pass on the cause, saw %v", err)
+	}
+
+	if !afterCancelled.Cancelled() {
+		test.Fatalf("Expected Finally() to pass on the cancellation")
+	}
+
+	ran := 0
+
+	Completed(10).Finally(func() { ran++ })
+	Rejected(expected).Finally(func() { ran++ })
+	completed.Finally(func() { ran++ })
+
+	if ran != 3 {
+		test.Fatalf("Expected Finally() to run immediately on settled promises")
+	}
+
+	_, err := Completed(10).Finally(func() {
+		panic("Expected panic!")
+	}).Get()
+
+	var panicked *PanicError
+
+	if !errors.As(err, &panicked) {
+		test.Fatalf("Expected a panicking Finally() to reject, saw %v", err)
+	}
+}
+
+// Validate that a hook given to Finally() runs once, even if the derived
+// promise is cancelled while it runs, and that cancelling the derived promise
+// does not run it before the promise it was composed from has settled.
+func TestFinallyOnce(test *testing.T) {
+	ran := 0
+
+	promise := Promise()
+
+	var finally Thenable
+
+	finally = promise.Finally(func() {
+		ran++
+
+		finally.(Completable).Cancel()
+	})
+
+	promise.Complete(10)
+
+	if ran != 1 {
+		test.Fatalf("Expected the hook to run once, saw %d", ran)
+	}
+
+	ran = 0
+	promise = Promise()
+
+	promise.Finally(func() {
+		ran++
+	}).(Completable).Cancel()
+
+	if ran != 0 {
+		test.Fatalf("Expected the hook to wait for the promise to settle")
+	}
+
+	promise.Complete(10)
+
+	if ran != 1 {
+		test.Fatalf("Expected the hook to run once the promise settled, saw %d", ran)
+	}
+
+	ran = 0
+	promise = Promise()
+
+	promise.Finally(func() {
+		ran++
+	})
+
+	promise.Cancel()
+
+	if ran != 1 {
+		test.Fatalf("Expected the hook to run once the promise was cancelled, saw %d", ran)
+	}
+}
+
+// Validate that the result of a promise captures its outcome, and that a
+// promise can be recreated from it.
+func TestResult(test *testing.T) {
+	var expected = errors.New("Expected error!")
+
+	completed := Promise()
+	rejected := Promise()
+	cancelled := Promise()
+
+	go completed.Complete(10)
+	go rejected.Reject(expected)
+	go cancelled.Cancel()
+
+	results := []Result{completed.Result(), rejected.Result(), cancelled.Result()}
+
+	expectedResults := []Result{
+		{Value: 10, State: FULFILLED},
+		{Cause: expected, State: REJECTED},
+		{Cause: ErrCancelled, State: CANCELLED},
+	}
+
+	for i, result := range results {
+		if result != expectedResults[i] {
+			test.Fatalf("Expected result %v, saw %v", expectedResults[i], result)
+		}
+
+		if FromResult(result).Result() != result {
+			test.Fatalf("Expected FromResult() to recreate %v", result)
+		}
+	}
+
+	if Completed(10).Result() != results[0] || Rejected(expected).Result() != results[1] {
+		test.Fatalf("Expected the results of pure promises to match")
+	}
+
+	if value, err := results[0].Get(); value != 10 || err != nil {
+		test.Fatalf("Expected Get() on a result to match the promise")
+	}
+
+	awaited := Promise()
+
+	go awaited.Complete(20)
+
+	if result := Await(awaited); result.Value != 20 || !result.Resolved() {
+		test.Fatalf("Expected Await() to return the outcome, saw %v", result)
+	}
+}
+
+// Validate that TryGet() never blocks, and reports whether or not the promise
+// has settled.
+func TestTryGet(test *testing.T) {
+	var expected = errors.New("Expected error!")
+
+	promise := Promise()
+
+	if _, _, settled := promise.TryGet(); settled {
+		test.Fatalf("Expected a pending promise not to be settled")
+	}
+
+	promise.Complete(10)
+
+	if value, err, settled := promise.TryGet(); value != 10 || err != nil || !settled {
+		test.Fatalf("Expected 10, saw %v, %v, %v", value, err, settled)
+	}
+
+	if value, err, settled := Completed(20).TryGet(); value != 20 || err != nil || !settled {
+		test.Fatalf("Expected 20, saw %v, %v, %v", value, err, settled)
+	}
+
+	if _, err, settled := Rejected(expected).TryGet(); err != expected || !settled {
+		test.Fatalf("Expected %v, saw %v, %v", expected, err, settled)
+	}
+
+	cancelled := Promise()
+
+	cancelled.Cancel()
+
+	if _, err, settled := cancelled.TryGet(); err != ErrCancelled || !settled {
+		test.Fatalf("Expected ErrCancelled, saw %v, %v", err, settled)
+	}
+}
+
+// Validate that of a number of producers racing to settle a promise, exactly
+// one wins, and that settling it twice panics unless it was cancelled.
+func TestTryComplete(test *testing.T) {
+	var expected = errors.New("Expected error!")
+	var wins int64
+	var group sync.WaitGroup
+
+	promise := Promise()
+
+	for i := 0; i < 16; i++ {
+		i := i
+
+		group.Add(1)
+
+		go func() {
+			defer group.Done()
+
+			var won bool
+
+			if i%2 == 0 {
+				won = promise.TryComplete(i)
+			} else {
+				won = promise.TryReject(expected)
+			}
+
+			if won {
+				atomic.AddInt64(&wins, 1)
+			}
+		}()
+	}
+
+	group.Wait()
+
+	if wins != 1 {
+		test.Fatalf("Expected exactly one producer to win, saw %d", wins)
+	}
+
+	if promise.TryComplete(20) || promise.TryReject(expected) {
+		test.Fatalf("Expected a settled promise not to be settled again")
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				test.Fatalf("Expected Complete() on a settled promise to panic")
+			}
+		}()
+
+		promise.Complete(20)
+	}()
+
+	cancelled := Promise()
+
+	cancelled.Cancel()
+
+	if cancelled.TryComplete(10) || cancelled.TryReject(expected) {
+		test.Fatalf("Expected a cancelled promise not to be settled")
+	}
+
+	derived := Promise()
+
+	rejected := derived.ThenTry(func(value interface{}) (interface{}, error) {
+		return nil, expected
+	})
+
+	if !derived.TryComplete(10) {
+		test.Fatalf("Expected TryComplete() to settle a pending promise")
+	}
+
+	if _, err := rejected.Get(); err != expected {
+		test.Fatalf("Expected %v, saw %v", expected, err)
+	}
+}
+
+// Validate that CompleteWith() settles a promise as another promise settles,
+// unless it is settled some other way first.
+func TestCompleteWith(test *testing.T) {
+	var expected = errors.New("Expected error!")
+
+	promise := Promise()
+	adopted := Promise()
+
+	promise.CompleteWith(adopted)
+
+	if _, _, settled := promise.TryGet(); settled {
+		test.Fatalf("Expected the promise to be pending until the adopted one settles")
+	}
+
+	adopted.Complete(10)
+
+	if value, _ := promise.Get(); value != 10 {
+		test.Fatalf("Expected 10, saw %v", value)
+	}
+
+	promise = Promise()
+
+	promise.CompleteWith(Rejected(expected))
+
+	if _, err := promise.Get(); err != expected {
+		test.Fatalf("Expected %v, saw %v", expected, err)
+	}
+
+	promise = Promise()
+	adopted = Promise()
+
+	promise.CompleteWith(adopted)
+
+	if !promise.TryComplete(20) {
+		test.Fatalf("Expected an adopting promise to be settled some other way")
+	}
+
+	adopted.Reject(expected)
+
+	if value, err := promise.Get(); value != 20 || err != nil {
+		test.Fatalf("Expected the adopted outcome to be ignored, saw %v, %v", value, err)
+	}
+
+	promise = Promise()
+	adopted = Promise()
+
+	promise.CompleteWith(adopted)
+	promise.Cancel()
+
+	if !adopted.Cancelled() {
+		test.Fatalf("Expected cancelling the promise to cancel the adopted one")
+	}
+
+	promise = Promise()
+	adopted = Promise()
+
+	promise.CompleteWith(adopted)
+	adopted.Cancel()
+
+	if !promise.Cancelled() {
+		test.Fatalf("Expected cancelling the adopted promise to cancel this one")
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				test.Fatalf("Expected a second CompleteWith() to panic")
+			}
+		}()
+
+		pending := Promise()
+
+		pending.CompleteWith(Promise())
+		pending.CompleteWith(Promise())
+	}()
+
+	defer func() {
+		if recover() == nil {
+			test.Fatalf("Expected CompleteWith() on a settled promise to panic")
+		}
+	}()
+
+	Promise().CompleteWith(Completed(10))
+
+	completed := Promise()
+
+	completed.Complete(10)
+	completed.CompleteWith(Completed(20))
+}
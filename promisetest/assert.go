@@ -0,0 +1,96 @@
+package promisetest
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/quantcast/promise"
+)
+
+// How long the assertions wait for a promise to settle before failing the
+// test. Promises settled by a Scheduler or a fake clock do not need to be
+// waited for at all, so this only matters for those settled by some other
+// goroutine.
+var Timeout = 5 * time.Second
+
+// Wait for the promise to settle, failing the test if it does not in time.
+func settle(test testing.TB, thenable promise.Thenable) (promise.Result, bool) {
+	test.Helper()
+
+	if _, _, settled := thenable.TryGet(); !settled {
+		// The promise may well be rejected with a *TimeoutError of its own, so
+		// there's no telling from the error whether or not it settled.
+		thenable.GetTimeout(Timeout)
+
+		if _, _, settled := thenable.TryGet(); !settled {
+			test.Errorf("Expected the promise to settle within %s", Timeout)
+
+			return promise.Result{}, false
+		}
+	}
+
+	return thenable.Result(), true
+}
+
+// Assert that the promise is completed with a value equal to the one given,
+// as reflect.DeepEqual() sees it.
+func AssertResolvedWith(test testing.TB, thenable promise.Thenable, expected interface{}) {
+	test.Helper()
+
+	result, ok := settle(test, thenable)
+
+	if !ok {
+		return
+	}
+
+	if result.State != promise.FULFILLED {
+		test.Errorf("Expected the promise to be completed with %v, but it failed with %v",
+			expected, result.Cause)
+
+		return
+	}
+
+	if !reflect.DeepEqual(result.Value, expected) {
+		test.Errorf("Expected the promise to be completed with %v, saw %v", expected, result.Value)
+	}
+}
+
+// Assert that the promise fails with the cause given, or with one which
+// wraps it, as errors.Is() sees it. Cancellation counts as failing with
+// promise.ErrCancelled.
+func AssertRejectedWith(test testing.TB, thenable promise.Thenable, expected error) {
+	test.Helper()
+
+	result, ok := settle(test, thenable)
+
+	if !ok {
+		return
+	}
+
+	if result.State == promise.FULFILLED {
+		test.Errorf("Expected the promise to fail with %v, but it was completed with %v",
+			expected, result.Value)
+
+		return
+	}
+
+	if !errors.Is(result.Cause, expected) {
+		test.Errorf("Expected the promise to fail with %v, saw %v", expected, result.Cause)
+	}
+}
+
+// Assert that the promise has not settled after running the given actions,
+// such as stepping a Scheduler or advancing a clock, in order.
+func AssertPendingAfter(test testing.TB, thenable promise.Thenable, actions ...func()) {
+	test.Helper()
+
+	for _, action := range actions {
+		action()
+	}
+
+	if _, _, settled := thenable.TryGet(); settled {
+		test.Errorf("Expected the promise to be pending, but it settled as %v", thenable.Result())
+	}
+}
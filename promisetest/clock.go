@@ -0,0 +1,27 @@
+package promisetest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/quantcast/promise"
+)
+
+// The time at which the clocks installed by InstallClock() start.
+var Epoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Install a fake clock, starting at Epoch, in place of the clock used by the
+// promise package, and restore the previous one once the test is over. Since
+// the clock is shared by the whole package, tests which install one must not
+// run in parallel. Tests which only need a clock for Retry() may instead give
+// a *promise.FakeClock to the RetryPolicy, and run in parallel.
+func InstallClock(test testing.TB) *promise.FakeClock {
+	clock := promise.NewFakeClock(Epoch)
+	previous := promise.SetClock(clock)
+
+	test.Cleanup(func() {
+		promise.SetClock(previous)
+	})
+
+	return clock
+}
@@ -0,0 +1,12 @@
+// Package promisetest provides utilities for testing code built on promises,
+// deterministically, without sleeping or waiting on goroutines.
+//
+// A Scheduler is an executor which runs nothing until it is told to, so that
+// the computations of the promises composed from a promise created with
+// promise.PromiseOn() can be run one at a time, in a controlled order, to
+// reproduce races. A fake clock stands in for the real one, so that timeouts,
+// retries and delays only happen when the test advances it. The assertions
+// check the outcome of a promise, reporting any mismatch through testing.TB,
+// and FailOnUnhandledRejection() fails a test which leaves a rejection
+// unhandled.
+package promisetest
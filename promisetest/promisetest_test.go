@@ -0,0 +1,181 @@
+package promisetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/quantcast/promise"
+)
+
+// A testing.TB which records failures instead of reporting them.
+type recorder struct {
+	testing.TB
+
+	failures []string
+	cleanups []func()
+}
+
+func (recorder *recorder) Helper() {}
+
+func (recorder *recorder) Cleanup(cleanup func()) {
+	recorder.cleanups = append(recorder.cleanups, cleanup)
+}
+
+// Run the cleanups, as though the test had finished.
+func (recorder *recorder) finish() {
+	for i := len(recorder.cleanups) - 1; i >= 0; i-- {
+		recorder.cleanups[i]()
+	}
+}
+
+func (recorder *recorder) Errorf(format string, args ...interface{}) {
+	recorder.failures = append(recorder.failures, fmt.Sprintf(format, args...))
+}
+
+// Validate that a scheduler runs the computations of promises one at a time,
+// only when it is stepped.
+func TestScheduler(test *testing.T) {
+	scheduler := NewScheduler()
+	source := promise.PromiseOn(scheduler)
+
+	var order []int
+
+	first := source.Then(func(value interface{}) interface{} {
+		order = append(order, 1)
+
+		return value.(int) + 1
+	})
+
+	second := source.Then(func(value interface{}) interface{} {
+		order = append(order, 2)
+
+		return value.(int) + 2
+	})
+
+	source.Complete(10)
+
+	AssertPendingAfter(test, first)
+	AssertPendingAfter(test, second)
+
+	if scheduler.Pending() != 2 {
+		test.Fatalf("Expected two tasks to be queued, saw %d", scheduler.Pending())
+	}
+
+	AssertPendingAfter(test, second, func() {
+		scheduler.Step()
+	})
+
+	AssertResolvedWith(test, first, 11)
+
+	if ran := scheduler.RunAll(); ran != 1 {
+		test.Fatalf("Expected one more task to run, saw %d", ran)
+	}
+
+	AssertResolvedWith(test, second, 12)
+
+	if len(order) != 2 || order[0] != 1 || order[1] != 2 {
+		test.Fatalf("Expected the computations to run in order, saw %v", order)
+	}
+
+	if scheduler.Step() {
+		test.Fatalf("Expected no tasks to be left")
+	}
+}
+
+// Validate that an installed clock controls timeouts and delays, and that it
+// is removed once the test is over.
+func TestInstallClock(test *testing.T) {
+	var clock *promise.FakeClock
+
+	test.Run("Installed", func(test *testing.T) {
+		clock = InstallClock(test)
+
+		delayed := promise.Delay(time.Minute, 10)
+		limited := promise.WithTimeout(promise.Promise(), time.Second)
+
+		AssertPendingAfter(test, delayed, func() {
+			clock.Advance(time.Second)
+		})
+
+		AssertRejectedWith(test, limited, context.DeadlineExceeded)
+
+		clock.Advance(time.Minute)
+
+		AssertResolvedWith(test, delayed, 10)
+	})
+
+	if _, err := promise.WithTimeout(promise.Promise(), time.Millisecond).Get(); err == nil {
+		test.Fatalf("Expected the real clock to be restored")
+	}
+}
+
+// Validate that the assertions fail when they should.
+func TestAssertions(test *testing.T) {
+	var expected = errors.New("Expected error!")
+
+	recorder := &recorder{TB: test}
+
+	AssertResolvedWith(recorder, promise.Completed([]int{1, 2}), []int{1, 2})
+	AssertRejectedWith(recorder, promise.Rejected(fmt.Errorf("Wrapped: %w", expected)), expected)
+
+	if len(recorder.failures) != 0 {
+		test.Fatalf("Expected the assertions to pass, saw %v", recorder.failures)
+	}
+
+	AssertResolvedWith(recorder, promise.Completed(10), 20)
+	AssertResolvedWith(recorder, promise.Rejected(expected), 10)
+	AssertRejectedWith(recorder, promise.Completed(10), expected)
+	AssertRejectedWith(recorder, promise.Rejected(errors.New("Other error!")), expected)
+	AssertPendingAfter(recorder, promise.Completed(10))
+
+	if len(recorder.failures) != 5 {
+		test.Fatalf("Expected every assertion to fail, saw %v", recorder.failures)
+	}
+
+	defer func(timeout time.Duration) {
+		Timeout = timeout
+	}(Timeout)
+
+	Timeout = time.Millisecond
+
+	AssertResolvedWith(recorder, promise.Promise(), 10)
+
+	if len(recorder.failures) != 6 {
+		test.Fatalf("Expected a promise which never settles to fail, saw %v", recorder.failures)
+	}
+}
+
+// Validate that strict mode fails the test for rejections which are never
+// handled, and only for those.
+func TestFailOnUnhandledRejection(test *testing.T) {
+	var expected = errors.New("Expected error!")
+
+	recorder := &recorder{TB: test}
+
+	FailOnUnhandledRejection(recorder)
+
+	handled := promise.Promise()
+	unhandled := promise.Promise()
+
+	handled.Catch(func(error) {})
+
+	handled.Reject(expected)
+	unhandled.Reject(expected)
+
+	recorder.finish()
+
+	if len(recorder.failures) != 1 {
+		test.Fatalf("Expected one unhandled rejection, saw %v", recorder.failures)
+	}
+
+	promise.Promise().Reject(expected)
+
+	promise.ReportUnhandledRejections()
+
+	if len(recorder.failures) != 1 {
+		test.Fatalf("Expected nothing to be reported after the test, saw %v", recorder.failures)
+	}
+}
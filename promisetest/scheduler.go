@@ -0,0 +1,71 @@
+package promisetest
+
+import (
+	"sync"
+)
+
+// An Executor which queues its tasks until they are run by calling Step() or
+// RunAll(), on the goroutine which calls them. Tasks run in the order they
+// were queued.
+type Scheduler struct {
+	mutex sync.Mutex
+	tasks []func()
+}
+
+// Create a scheduler with no tasks queued.
+func NewScheduler() *Scheduler {
+	return new(Scheduler)
+}
+
+// Queue the task, to be run by Step() or RunAll().
+func (scheduler *Scheduler) Execute(task func()) {
+	scheduler.mutex.Lock()
+
+	scheduler.tasks = append(scheduler.tasks, task)
+
+	scheduler.mutex.Unlock()
+}
+
+// Return the number of tasks queued.
+func (scheduler *Scheduler) Pending() int {
+	scheduler.mutex.Lock()
+
+	defer scheduler.mutex.Unlock()
+
+	return len(scheduler.tasks)
+}
+
+// Run the task at the head of the queue, if there is one, and return whether
+// there was.
+func (scheduler *Scheduler) Step() bool {
+	scheduler.mutex.Lock()
+
+	if len(scheduler.tasks) == 0 {
+		scheduler.mutex.Unlock()
+
+		return false
+	}
+
+	task := scheduler.tasks[0]
+
+	scheduler.tasks[0] = nil
+	scheduler.tasks = scheduler.tasks[1:]
+
+	scheduler.mutex.Unlock()
+
+	task()
+
+	return true
+}
+
+// Run tasks until there are none left, including those queued by the tasks
+// themselves, and return how many were run.
+func (scheduler *Scheduler) RunAll() int {
+	ran := 0
+
+	for scheduler.Step() {
+		ran++
+	}
+
+	return ran
+}
@@ -0,0 +1,40 @@
+package promisetest
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/quantcast/promise"
+)
+
+// Fail the test for every rejection which is never handled, for the duration
+// of the test. Rejections which are still unhandled when the test is over are
+// reported then, so that none are missed. Since the hook is shared by the
+// whole package, tests which use this must not run in parallel.
+func FailOnUnhandledRejection(test testing.TB) {
+	var mutex sync.Mutex
+	var finished bool
+
+	previous := promise.OnUnhandledRejection(func(thenable promise.Thenable, cause error) {
+		mutex.Lock()
+
+		defer mutex.Unlock()
+
+		// A promise may be garbage collected long after the test is over.
+		if !finished {
+			test.Errorf("Unhandled rejection: %v", cause)
+		}
+	})
+
+	test.Cleanup(func() {
+		promise.ReportUnhandledRejections()
+
+		mutex.Lock()
+
+		finished = true
+
+		mutex.Unlock()
+
+		promise.OnUnhandledRejection(previous)
+	})
+}
@@ -1,5 +1,10 @@
 package promise
 
+import (
+	"context"
+	"time"
+)
+
 type RejectedPromise struct {
 	cause error
 }
@@ -28,20 +33,76 @@ func (promise *RejectedPromise) Rejected() bool {
 	return true
 }
 
+func (promise *RejectedPromise) Cancelled() bool {
+	return false
+}
+
 func (promise *RejectedPromise) Get() (interface{}, error) {
 	return nil, promise.cause
 }
 
+func (promise *RejectedPromise) TryGet() (interface{}, error, bool) {
+	return nil, promise.cause, true
+}
+
+func (promise *RejectedPromise) Result() Result {
+	return Result{Cause: promise.cause, State: REJECTED}
+}
+
+func (promise *RejectedPromise) GetContext(ctx context.Context) (interface{}, error) {
+	return promise.Get()
+}
+
+func (promise *RejectedPromise) GetTimeout(timeout time.Duration) (interface{}, error) {
+	return promise.Get()
+}
+
 func (promise *RejectedPromise) Then(compute func(interface{}) interface{}) Thenable {
 	return promise
 }
 
+func (promise *RejectedPromise) ThenTry(compute func(interface{}) (interface{}, error)) Thenable {
+	return promise
+}
+
+func (promise *RejectedPromise) ThenOn(executor Executor, compute func(interface{}) interface{}) Thenable {
+	return promise
+}
+
 func (promise *RejectedPromise) Combine(compute func(interface{}) Thenable) Thenable {
 	return promise
 }
 
 func (promise *RejectedPromise) Catch(handle func(error)) Thenable {
-	handle(promise.cause)
+	if _, cause := tryRecover(observer(handle), promise.cause); cause != promise.cause {
+		return Rejected(cause)
+	}
 
 	return promise
 }
+
+// Run the hook immediately, and return this promise.
+func (promise *RejectedPromise) Finally(hook func()) Thenable {
+	if cause := tryFinally(hook); cause != nil {
+		return Rejected(cause)
+	}
+
+	return promise
+}
+
+// Create a promise which is rejected with the same cause once the duration has
+// elapsed.
+func (promise *RejectedPromise) Delay(duration time.Duration) Thenable {
+	return delay(promise, duration)
+}
+
+// Create a completed promise if the handler recovers from the cause of this
+// promise, or a rejected one with the error it returns otherwise.
+func (promise *RejectedPromise) Recover(handle func(error) (interface{}, error)) Thenable {
+	return settled(tryRecover(handle, promise.cause))
+}
+
+// Return the promise created to replace this one.
+func (promise *RejectedPromise) RecoverWith(create func(error) Thenable) Thenable {
+	return tryReplace(create, promise.cause)
+}
@@ -0,0 +1,51 @@
+package promise
+
+// The outcome of a promise which has settled.
+// A Result is a plain value, which can be stored, compared, or sent over a
+// channel, unlike the promise it came from. Its State is one of FULFILLED,
+// REJECTED or CANCELLED, and determines which of Value or Cause is meaningful.
+type Result struct {
+	Value interface{}
+	Cause error
+	State uint32
+}
+
+// Return the value of the promise, or the cause of its failure, as Get() on
+// the promise would.
+func (result Result) Get() (interface{}, error) {
+	return result.Value, result.Cause
+}
+
+func (result Result) Resolved() bool {
+	return result.State == FULFILLED
+}
+
+func (result Result) Rejected() bool {
+	return result.State == REJECTED
+}
+
+func (result Result) Cancelled() bool {
+	return result.State == CANCELLED
+}
+
+// Block until the given promise has settled, and return its outcome. This is
+// the same as calling Result() on the promise, for code which would rather
+// not deal in promises at all, such as at the edge of a program built on them.
+func Await(thenable Thenable) Result {
+	return thenable.Result()
+}
+
+// Create a promise which has already settled with the given result. A result
+// which is not settled is an illegal state.
+func FromResult(result Result) Thenable {
+	switch result.State {
+	case FULFILLED:
+		return Completed(result.Value)
+	case REJECTED:
+		return Rejected(result.Cause)
+	case CANCELLED:
+		return cancelled()
+	}
+
+	panic("Invalid state")
+}
@@ -0,0 +1,244 @@
+package promise
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// A strategy for how long to wait before retrying, given the number of the
+// attempt which just failed, counting from zero.
+type Backoff func(attempt int) time.Duration
+
+// Wait the same amount of time before every retry.
+func FixedBackoff(delay time.Duration) Backoff {
+	return func(int) time.Duration {
+		return delay
+	}
+}
+
+// Wait twice as long before each retry as before the previous one, starting
+// with the initial delay, but never longer than the maximum. A maximum of zero
+// or less means there is no maximum.
+func ExponentialBackoff(initial, maximum time.Duration) Backoff {
+	return func(attempt int) time.Duration {
+		delay := initial
+
+		for i := 0; i < attempt; i++ {
+			delay *= 2
+
+			if maximum > 0 && delay >= maximum {
+				return maximum
+			}
+
+			// Give up doubling well before the delay overflows.
+			if delay > time.Duration(1)<<60 {
+				break
+			}
+		}
+
+		if maximum > 0 && delay > maximum {
+			return maximum
+		}
+
+		return delay
+	}
+}
+
+// Wait somewhere between half and all of the delay the given backoff would
+// wait, chosen at random, so that clients which failed at the same time do not
+// all retry at the same time as well.
+func JitteredBackoff(backoff Backoff) Backoff {
+	return func(attempt int) time.Duration {
+		delay := backoff(attempt)
+
+		if delay <= 1 {
+			return delay
+		}
+
+		return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+	}
+}
+
+// How Retry() goes about retrying.
+type RetryPolicy struct {
+	// The maximum number of attempts. At least one attempt is always made.
+	Attempts int
+
+	// How long to wait between attempts. With none, the next attempt is made
+	// as soon as the previous one fails.
+	Backoff Backoff
+
+	// How long each attempt may take before it is cancelled, and counted as
+	// failed with a *TimeoutError. Zero means attempts may take as long as
+	// they like.
+	Timeout time.Duration
+
+	// Whether or not it's worth retrying after an attempt fails with the given
+	// cause. With none, every failure is retried.
+	Retryable func(error) bool
+
+	// The Clock used to wait between attempts, and to time each attempt out.
+	// With none, the Clock set by SetClock() is used.
+	Clock Clock
+}
+
+// Create a promise for the outcome of an attempt at some fallible operation,
+// which is retried according to the policy for as long as it fails. The
+// attempt is given the number of the attempt, counting from zero.
+//
+// The promise is completed with the value of the first attempt to succeed.
+// Otherwise, once the attempts are exhausted, or an attempt fails with a cause
+// which is not retryable, it is rejected with a *MultiError holding the cause
+// of every failed attempt, indexed by the number of the attempt. Cancelling
+// the promise cancels the attempt in progress, if there is one, and makes no
+// further attempts.
+//
+// Waiting between attempts, and the timeout of each attempt, use the Clock of
+// the policy, or the Clock set by SetClock() if it has none.
+func Retry(policy RetryPolicy, attempt func(n int) Thenable) Thenable {
+	retrying := &retrying{
+		policy:  policy,
+		attempt: attempt,
+		promise: completable(nil, nil),
+	}
+
+	retrying.promise.OnCancel(retrying.stop)
+
+	retrying.run(0)
+
+	return retrying.promise
+}
+
+// The state of a promise created by Retry().
+type retrying struct {
+	policy  RetryPolicy
+	attempt func(int) Thenable
+	promise *CompletablePromise
+
+	mutex   sync.Mutex
+	causes  []*IndexedError
+	current Thenable
+	timer   Timer
+}
+
+// Make an attempt, unless the promise has been cancelled.
+func (retrying *retrying) run(n int) {
+	if retrying.promise.Cancelled() {
+		return
+	}
+
+	current := tryCreate(func(interface{}) Thenable {
+		return retrying.attempt(n)
+	}, nil)
+
+	// The attempt itself is what's cancelled when the promise is, which the
+	// timeout, if there is one, then observes.
+	outcome := current
+
+	if retrying.policy.Timeout > 0 {
+		outcome = withTimeout(retrying.clock(), current, retrying.policy.Timeout)
+	}
+
+	retrying.mutex.Lock()
+
+	// The promise may have been cancelled while the attempt was being made,
+	// in which case stop() had nothing to cancel.
+	if retrying.promise.Cancelled() {
+		retrying.mutex.Unlock()
+
+		cancel(current)
+
+		return
+	}
+
+	retrying.current = current
+
+	retrying.mutex.Unlock()
+
+	watch(outcome, func(result Result) {
+		retrying.settled(n, result)
+	})
+}
+
+// Either settle the promise with the outcome of an attempt, or retry.
+func (retrying *retrying) settled(n int, result Result) {
+	if result.Cause == nil {
+		retrying.promise.TryComplete(result.Value)
+
+		return
+	}
+
+	retryable := retrying.policy.Retryable == nil || retrying.policy.Retryable(result.Cause)
+
+	retrying.mutex.Lock()
+
+	retrying.causes = append(retrying.causes, &IndexedError{Index: n, Cause: result.Cause})
+	retrying.current = nil
+
+	causes := retrying.causes
+
+	retrying.mutex.Unlock()
+
+	if !retryable || n+1 >= retrying.policy.Attempts {
+		retrying.promise.TryReject(&MultiError{Errors: causes})
+
+		return
+	}
+
+	var delay time.Duration
+
+	if retrying.policy.Backoff != nil {
+		delay = retrying.policy.Backoff(n)
+	}
+
+	if delay <= 0 {
+		retrying.run(n + 1)
+
+		return
+	}
+
+	timer := retrying.clock().AfterFunc(delay, func() {
+		retrying.run(n + 1)
+	})
+
+	retrying.mutex.Lock()
+
+	retrying.timer = timer
+	cancelled := retrying.promise.Cancelled()
+
+	retrying.mutex.Unlock()
+
+	// The promise may have been cancelled before there was a timer to stop.
+	if cancelled {
+		timer.Stop()
+	}
+}
+
+// Return the Clock to wait with.
+func (retrying *retrying) clock() Clock {
+	if retrying.policy.Clock != nil {
+		return retrying.policy.Clock
+	}
+
+	return now()
+}
+
+// Stop retrying, once the promise has been cancelled.
+func (retrying *retrying) stop() {
+	retrying.mutex.Lock()
+
+	current, timer := retrying.current, retrying.timer
+
+	retrying.current, retrying.timer = nil, nil
+
+	retrying.mutex.Unlock()
+
+	if timer != nil {
+		timer.Stop()
+	}
+
+	if current != nil {
+		cancel(current)
+	}
+}
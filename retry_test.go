@@ -0,0 +1,179 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// Validate the delays of the backoff policies.
+func TestBackoff(test *testing.T) {
+	fixed := FixedBackoff(time.Second)
+
+	if fixed(0) != time.Second || fixed(10) != time.Second {
+		test.Fatalf("Expected a fixed backoff of a second")
+	}
+
+	exponential := ExponentialBackoff(time.Second, 10*time.Second)
+	expected := []time.Duration{1, 2, 4, 8, 10, 10}
+
+	for i, delay := range expected {
+		if exponential(i) != delay*time.Second {
+			test.Fatalf("Expected %s before attempt %d, saw %s", delay*time.Second, i, exponential(i))
+		}
+	}
+
+	if ExponentialBackoff(time.Second, 0)(1000) <= 0 {
+		test.Fatalf("Expected an unbounded backoff not to overflow")
+	}
+
+	jittered := JitteredBackoff(fixed)
+
+	for i := 0; i < 100; i++ {
+		if delay := jittered(i); delay < time.Second/2 || delay > time.Second {
+			test.Fatalf("Expected a jittered delay within bounds, saw %s", delay)
+		}
+	}
+}
+
+// Validate that an attempt which fails is retried, after waiting.
+func TestRetry(test *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+
+	defer SetClock(SetClock(clock))
+
+	var expected = errors.New("Expected error!")
+	var attempts []int
+
+	retried := Retry(RetryPolicy{
+		Attempts: 5,
+		Backoff:  FixedBackoff(time.Second),
+	}, func(n int) Thenable {
+		attempts = append(attempts, n)
+
+		if n < 2 {
+			return Rejected(expected)
+		}
+
+		return Completed(n)
+	})
+
+	if len(attempts) != 1 || retried.Resolved() {
+		test.Fatalf("Expected one attempt before the clock advanced, saw %v", attempts)
+	}
+
+	clock.Advance(time.Second)
+	clock.Advance(time.Second)
+
+	if value, err := retried.Get(); value != 2 || err != nil {
+		test.Fatalf("Expected 2, saw %v, %v", value, err)
+	}
+
+	if len(attempts) != 3 {
+		test.Fatalf("Expected three attempts, saw %v", attempts)
+	}
+}
+
+// Validate that the rejection of a promise which failed every attempt carries
+// the cause of every attempt, and that causes which are not retryable stop it
+// early.
+func TestRetryExhausted(test *testing.T) {
+	var expected = errors.New("Expected error!")
+	var fatal = errors.New("Fatal error!")
+
+	_, err := Retry(RetryPolicy{Attempts: 3}, func(n int) Thenable {
+		return Rejected(expected)
+	}).Get()
+
+	var multi *MultiError
+
+	if !errors.As(err, &multi) || len(multi.Errors) != 3 || multi.Cause(2) != expected {
+		test.Fatalf("Expected every cause, saw %v", err)
+	}
+
+	attempts := 0
+
+	_, err = Retry(RetryPolicy{
+		Attempts: 3,
+		Retryable: func(cause error) bool {
+			return cause != fatal
+		},
+	}, func(n int) Thenable {
+		attempts++
+
+		return Rejected(fatal)
+	}).Get()
+
+	if attempts != 1 || !errors.Is(err, fatal) {
+		test.Fatalf("Expected a single attempt, saw %d, %v", attempts, err)
+	}
+
+	if _, err := Retry(RetryPolicy{}, func(int) Thenable { panic("Expected panic!") }).Get(); err == nil {
+		test.Fatalf("Expected a panicking attempt to be rejected")
+	}
+}
+
+// Validate that an attempt which takes too long is cancelled, and that
+// cancelling the promise cancels the attempt in progress and stops retrying.
+func TestRetryTimeout(test *testing.T) {
+	// The policy has a clock of its own, rather than replacing the clock of
+	// the whole package, so this test may run alongside others.
+	test.Parallel()
+
+	clock := NewFakeClock(time.Unix(0, 0))
+
+	var attempts []Completable
+
+	retried := Retry(RetryPolicy{
+		Attempts: 3,
+		Backoff:  FixedBackoff(time.Second),
+		Timeout:  time.Minute,
+		Clock:    clock,
+	}, func(n int) Thenable {
+		attempt := Promise()
+
+		attempts = append(attempts, attempt)
+
+		return attempt
+	})
+
+	clock.Advance(time.Minute)
+
+	if !attempts[0].Cancelled() {
+		test.Fatalf("Expected the attempt which timed out to be cancelled")
+	}
+
+	clock.Advance(time.Second)
+
+	if len(attempts) != 2 {
+		test.Fatalf("Expected a second attempt, saw %d", len(attempts))
+	}
+
+	retried.(Completable).Cancel()
+
+	if !attempts[1].Cancelled() {
+		test.Fatalf("Expected the attempt in progress to be cancelled")
+	}
+
+	clock.Advance(time.Hour)
+
+	if len(attempts) != 2 {
+		test.Fatalf("Expected no more attempts, saw %d", len(attempts))
+	}
+
+	if _, err := retried.Get(); err != ErrCancelled {
+		test.Fatalf("Expected the promise to be cancelled, saw %v", err)
+	}
+
+	timedOut := Retry(RetryPolicy{Attempts: 1, Timeout: time.Minute, Clock: clock}, func(int) Thenable {
+		return Promise()
+	})
+
+	clock.Advance(time.Minute)
+
+	var timeout *TimeoutError
+
+	if _, err := timedOut.Get(); !errors.As(err, &timeout) {
+		test.Fatalf("Expected a *TimeoutError, saw %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package promise
+
+import (
+	"time"
+)
+
+// Create a promise which settles as the given thenable does, unless it takes
+// longer than the duration to do so, in which case the promise is rejected
+// with a *TimeoutError and the thenable is cancelled, if it can be, so that
+// whatever was producing its value can stop. If the thenable is cancelled, so
+// is the promise returned. Cancelling the promise returned stops the timer,
+// and stops observing the thenable, but does not cancel it.
+//
+// Unlike GetTimeout(), which only stops waiting, the promise returned can be
+// composed like any other, such as within the computation passed to Combine().
+// The timer uses the Clock set by SetClock().
+func WithTimeout(thenable Thenable, duration time.Duration) Thenable {
+	return withTimeout(now(), thenable, duration)
+}
+
+// Limit the time the thenable may take to settle, as WithTimeout() does, with
+// the given Clock.
+func withTimeout(clock Clock, thenable Thenable, duration time.Duration) Thenable {
+	limited := completable(nil, nil)
+	watches := new(watches)
+
+	timer := clock.AfterFunc(duration, func() {
+		if !limited.TryReject(&TimeoutError{Duration: duration}) {
+			return
+		}
+
+		watches.stop()
+
+		cancel(thenable)
+	})
+
+	limited.OnCancel(func() {
+		timer.Stop()
+
+		watches.stop()
+	})
+
+	watches.watch(thenable, func(result Result) {
+		if result.State == CANCELLED {
+			limited.Cancel()
+
+			return
+		}
+
+		if limited.resolve(result.Value, result.Cause) {
+			timer.Stop()
+		}
+	})
+
+	return limited
+}
+
+// Create a promise which settles as the given thenable does, unless it has not
+// settled by the deadline, as WithTimeout() does.
+func WithDeadline(thenable Thenable, deadline time.Time) Thenable {
+	return WithTimeout(thenable, deadline.Sub(now().Now()))
+}
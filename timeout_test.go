@@ -0,0 +1,108 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// Validate that a promise which takes too long is rejected with a
+// *TimeoutError, and cancelled.
+func TestWithTimeout(test *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+
+	defer SetClock(SetClock(clock))
+
+	source := Promise()
+	hooked := false
+
+	source.OnCancel(func() {
+		hooked = true
+	})
+
+	limited := WithTimeout(source, time.Second)
+
+	clock.Advance(time.Second - 1)
+
+	if limited.Rejected() {
+		test.Fatalf("Expected the promise not to be rejected before the timeout")
+	}
+
+	clock.Advance(1)
+
+	var timeout *TimeoutError
+
+	if _, err := limited.Get(); !errors.As(err, &timeout) || timeout.Duration != time.Second {
+		test.Fatalf("Expected a *TimeoutError, saw %v", err)
+	}
+
+	if !source.Cancelled() || !hooked {
+		test.Fatalf("Expected the source to be cancelled")
+	}
+
+	completed := Promise()
+	limited = WithTimeout(completed, time.Second)
+
+	completed.Complete(10)
+
+	clock.Advance(time.Hour)
+
+	if value, err := limited.Get(); value != 10 || err != nil {
+		test.Fatalf("Expected 10, saw %v, %v", value, err)
+	}
+
+	if clock.Timers() != 0 {
+		test.Fatalf("Expected the timer to be stopped")
+	}
+
+	abandoned := Promise()
+	limited = WithTimeout(abandoned, time.Second)
+
+	limited.(Completable).Cancel()
+
+	clock.Advance(time.Hour)
+
+	if abandoned.Cancelled() || abandoned.Rejected() {
+		test.Fatalf("Expected cancelling the timeout not to cancel the source")
+	}
+
+	cancelled := Promise()
+	limited = WithTimeout(cancelled, time.Second)
+
+	cancelled.Cancel()
+
+	if !limited.Cancelled() || clock.Timers() != 0 {
+		test.Fatalf("Expected cancelling the source to cancel the timeout")
+	}
+}
+
+// Validate that a deadline can be composed into a chain of promises.
+func TestWithDeadline(test *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+
+	defer SetClock(SetClock(clock))
+
+	deadline := clock.Now().Add(time.Minute)
+
+	never := Promise()
+
+	chained := Completed(10).Combine(func(value interface{}) Thenable {
+		return WithDeadline(never, deadline)
+	}).Then(func(value interface{}) interface{} {
+		test.Fatalf("Expected the chain to be rejected")
+
+		return nil
+	})
+
+	clock.Advance(time.Minute)
+
+	var timeout *TimeoutError
+
+	if _, err := chained.Get(); !errors.As(err, &timeout) || timeout.Duration != time.Minute {
+		test.Fatalf("Expected a *TimeoutError, saw %v", err)
+	}
+
+	if !never.Cancelled() {
+		test.Fatalf("Expected the source to be cancelled")
+	}
+}
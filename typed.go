@@ -0,0 +1,316 @@
+package promise
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// A promise for a value of a known type.
+// Typed wraps a Thenable so that values flowing through it are of type T,
+// rather than interface{}. The underlying Thenable is always available through
+// Untyped(), and any Thenable may be wrapped with Wrap(), so the two APIs can be
+// used together while code is migrated from one to the other.
+//
+// The names Promise and Completable are taken by the untyped API, hence Typed
+// and TypedCompletable.
+type Typed[T any] struct {
+	thenable Thenable
+}
+
+// A typed promise which can be completed.
+type TypedCompletable[T any] struct {
+	*Typed[T]
+
+	completable Completable
+}
+
+// Wrap an existing Thenable as a promise for a value of type T. If the value
+// the Thenable produces is not a T, the wrapped promise is rejected with a
+// *TypeError. A nil value is treated as the zero value of T.
+func Wrap[T any](thenable Thenable) *Typed[T] {
+	return &Typed[T]{thenable: thenable}
+}
+
+// Wrap an existing Completable as a completable promise for values of type T.
+func WrapCompletable[T any](completable Completable) *TypedCompletable[T] {
+	return &TypedCompletable[T]{
+		Typed:       Wrap[T](completable),
+		completable: completable,
+	}
+}
+
+// Generate a new completable promise for a value of type T. This is the typed
+// equivalent of Promise().
+func PromiseOf[T any]() *TypedCompletable[T] {
+	return WrapCompletable[T](Promise())
+}
+
+// Create a typed promise which is already completed with the given value. This
+// is the typed equivalent of Completed().
+func CompletedOf[T any](value T) *Typed[T] {
+	return Wrap[T](Completed(value))
+}
+
+// Create a typed promise which has already been rejected with the given cause.
+// This is the typed equivalent of Rejected().
+func RejectedOf[T any](cause error) *Typed[T] {
+	return Wrap[T](Rejected(cause))
+}
+
+// An error describing a value which was not of the type a Typed promise
+// expected.
+type TypeError struct {
+	Value    interface{}
+	Expected string
+}
+
+func (err *TypeError) Error() string {
+	return fmt.Sprintf("promise: expected a value of type %s, saw %T",
+		err.Expected, err.Value)
+}
+
+// Convert an untyped value into a T, failing if it has some other type.
+func cast[T any](value interface{}) (T, error) {
+	var zero T
+
+	if value == nil {
+		return zero, nil
+	}
+
+	typed, ok := value.(T)
+
+	if !ok {
+		return zero, &TypeError{
+			Value:    value,
+			Expected: reflect.TypeOf((*T)(nil)).Elem().String(),
+		}
+	}
+
+	return typed, nil
+}
+
+// Return the underlying Thenable.
+func (promise *Typed[T]) Untyped() Thenable {
+	return promise.thenable
+}
+
+// Determine whether or not calling Get() is safe.
+func (promise *Typed[T]) Resolved() bool {
+	return promise.thenable.Resolved()
+}
+
+// Determine whether or not the promise has been rejected.
+func (promise *Typed[T]) Rejected() bool {
+	return promise.thenable.Rejected()
+}
+
+// Determine whether or not the promise has been cancelled.
+func (promise *Typed[T]) Cancelled() bool {
+	return promise.thenable.Cancelled()
+}
+
+// Return the value of the promise, or the cause of its rejection. Blocks in
+// the same manner as the underlying Thenable.
+func (promise *Typed[T]) Get() (T, error) {
+	return typedResult[T](promise.thenable.Get())
+}
+
+// Return the value of the promise, or the cause of its rejection, without
+// blocking. See Thenable.TryGet().
+func (promise *Typed[T]) TryGet() (T, error, bool) {
+	value, err, settled := promise.thenable.TryGet()
+
+	if !settled {
+		var zero T
+
+		return zero, nil, false
+	}
+
+	typed, err := typedResult[T](value, err)
+
+	return typed, err, true
+}
+
+// Return the outcome of the promise, once it has settled. The value of the
+// result is not converted to a T.
+func (promise *Typed[T]) Result() Result {
+	return promise.thenable.Result()
+}
+
+// Return the value of the promise, or the cause of its rejection, giving up
+// when the context is done. See Thenable.GetContext().
+func (promise *Typed[T]) GetContext(ctx context.Context) (T, error) {
+	return typedResult[T](promise.thenable.GetContext(ctx))
+}
+
+// Return the value of the promise, or the cause of its rejection, giving up
+// after the timeout. See Thenable.GetTimeout().
+func (promise *Typed[T]) GetTimeout(timeout time.Duration) (T, error) {
+	return typedResult[T](promise.thenable.GetTimeout(timeout))
+}
+
+// Convert the result of one of the Get() methods of a Thenable.
+func typedResult[T any](value interface{}, err error) (T, error) {
+	if err != nil {
+		var zero T
+
+		return zero, err
+	}
+
+	return cast[T](value)
+}
+
+// Compose this promise with a transformation which preserves its type. Use
+// Map() for transformations which produce some other type.
+func (promise *Typed[T]) Then(compute func(T) T) *Typed[T] {
+	return Map(promise, compute)
+}
+
+// Compose this promise with a transformation which preserves its type, and
+// which may fail. Use TryMap() for transformations which produce some other
+// type.
+func (promise *Typed[T]) ThenTry(compute func(T) (T, error)) *Typed[T] {
+	return TryMap(promise, compute)
+}
+
+// Handle an error which has occurred during processing.
+func (promise *Typed[T]) Catch(handle func(error)) *Typed[T] {
+	return Wrap[T](promise.thenable.Catch(handle))
+}
+
+// Handle an error which has occurred during processing, by either returning a
+// value with which to complete the new promise, or an error with which to
+// reject it.
+func (promise *Typed[T]) Recover(handle func(error) (T, error)) *Typed[T] {
+	return Wrap[T](promise.thenable.Recover(func(cause error) (interface{}, error) {
+		return handle(cause)
+	}))
+}
+
+// Handle an error which has occurred during processing, by returning a promise
+// which takes the place of this one.
+func (promise *Typed[T]) RecoverWith(create func(error) *Typed[T]) *Typed[T] {
+	return Wrap[T](promise.thenable.RecoverWith(func(cause error) Thenable {
+		return create(cause).thenable
+	}))
+}
+
+// Run a hook once this promise settles, however it settles.
+func (promise *Typed[T]) Finally(hook func()) *Typed[T] {
+	return Wrap[T](promise.thenable.Finally(hook))
+}
+
+// Settle as this promise does, once the duration has elapsed after it settled.
+func (promise *Typed[T]) Delay(duration time.Duration) *Typed[T] {
+	return Wrap[T](promise.thenable.Delay(duration))
+}
+
+// Complete this promise with a given value.
+func (promise *TypedCompletable[T]) Complete(value T) {
+	promise.completable.Complete(value)
+}
+
+// Reject this promise and all of its derivatives.
+func (promise *TypedCompletable[T]) Reject(cause error) {
+	promise.completable.Reject(cause)
+}
+
+// Complete this promise with a given value, unless it has already settled.
+// Returns whether or not it was this call which settled it.
+func (promise *TypedCompletable[T]) TryComplete(value T) bool {
+	return promise.completable.TryComplete(value)
+}
+
+// Reject this promise, unless it has already settled. Returns whether or not
+// it was this call which settled it.
+func (promise *TypedCompletable[T]) TryReject(cause error) bool {
+	return promise.completable.TryReject(cause)
+}
+
+// Settle this promise as the given promise settles.
+func (promise *TypedCompletable[T]) CompleteWith(typed *Typed[T]) {
+	promise.completable.CompleteWith(typed.thenable)
+}
+
+// Cancel this promise and all of its derivatives.
+func (promise *TypedCompletable[T]) Cancel() {
+	promise.completable.Cancel()
+}
+
+// Register a hook to run when this promise is cancelled.
+func (promise *TypedCompletable[T]) OnCancel(hook func()) {
+	promise.completable.OnCancel(hook)
+}
+
+// Return the underlying Completable.
+func (promise *TypedCompletable[T]) Completable() Completable {
+	return promise.completable
+}
+
+// Create a promise for the result of applying compute to the value of the given
+// promise. This is the typed equivalent of Then(), which as a method cannot
+// introduce the new type parameter U.
+func Map[T, U any](promise *Typed[T], compute func(T) U) *Typed[U] {
+	return TryMap(promise, func(value T) (U, error) {
+		return compute(value), nil
+	})
+}
+
+// Create a promise for the result of applying compute to the value of the given
+// promise, which is rejected if compute returns an error. This is the typed
+// equivalent of ThenTry().
+func TryMap[T, U any](promise *Typed[T], compute func(T) (U, error)) *Typed[U] {
+	return Wrap[U](promise.thenable.ThenTry(func(value interface{}) (interface{}, error) {
+		typed, err := cast[T](value)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return compute(typed)
+	}))
+}
+
+// Create a promise which is completed with the result of the promise returned
+// by create. This is the typed equivalent of Combine().
+func FlatMap[T, U any](promise *Typed[T], create func(T) *Typed[U]) *Typed[U] {
+	return Wrap[U](promise.thenable.Combine(func(value interface{}) Thenable {
+		typed, err := cast[T](value)
+
+		if err != nil {
+			return Rejected(err)
+		}
+
+		return create(typed).thenable
+	}))
+}
+
+// Combine the given promises as a single promise which produces a slice of
+// their values, in order. This is the typed equivalent of All().
+func AllOf[T any](promises ...*Typed[T]) *Typed[[]T] {
+	thenables := make([]Thenable, len(promises))
+
+	for i, each := range promises {
+		thenables[i] = each.thenable
+	}
+
+	if len(thenables) == 0 {
+		return CompletedOf([]T{})
+	}
+
+	return TryMap(Wrap[[]interface{}](All(thenables...)), func(values []interface{}) ([]T, error) {
+		typed := make([]T, len(values))
+
+		for i, value := range values {
+			var err error
+
+			if typed[i], err = cast[T](value); err != nil {
+				return nil, err
+			}
+		}
+
+		return typed, nil
+	})
+}
@@ -0,0 +1,141 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+)
+
+// Ensure that typed promises compose, and produce values of the right type.
+func TestTypedPromise(test *testing.T) {
+	promise := PromiseOf[int]()
+
+	squared := promise.Then(func(value int) int {
+		return value * value
+	})
+
+	described := Map(squared, func(value int) string {
+		if value == 4 {
+			return "four"
+		}
+
+		return "something else"
+	})
+
+	combined := FlatMap(promise.Typed, func(value int) *Typed[int] {
+		return CompletedOf(value + 3)
+	})
+
+	go promise.Complete(2)
+
+	four, err := squared.Get()
+
+	if err != nil || four != 4 {
+		test.Fatalf("Expected result of 2² to be 4, saw %d (%v)", four, err)
+	}
+
+	description, _ := described.Get()
+
+	if description != "four" {
+		test.Fatalf("Expected \"four\", saw %q", description)
+	}
+
+	five, _ := combined.Get()
+
+	if five != 5 {
+		test.Fatalf("Expected result of 2 + 3 to be 5, saw %d", five)
+	}
+}
+
+// Validate that AllOf produces a typed slice in the order of its inputs.
+func TestAllOf(test *testing.T) {
+	values, err := AllOf(CompletedOf(1), CompletedOf(2), CompletedOf(3)).Get()
+
+	if err != nil {
+		test.Fatalf("Unexpected error: %s", err)
+	}
+
+	for i, value := range values {
+		if value != i+1 {
+			test.Fatalf("Expected %d at %d, saw %d", i+1, i, value)
+		}
+	}
+
+	empty, _ := AllOf[string]().Get()
+
+	if empty == nil || len(empty) != 0 {
+		test.Fatalf("Expected an empty slice for no promises")
+	}
+}
+
+// Validate that typed and untyped promises can be used interchangeably.
+func TestTypedInterop(test *testing.T) {
+	untyped := Promise()
+
+	typed := WrapCompletable[int](untyped)
+
+	doubled := untyped.Then(func(value interface{}) interface{} {
+		return value.(int) * 2
+	})
+
+	typed.Complete(21)
+
+	answer, _ := Wrap[int](doubled).Get()
+
+	if answer != 42 {
+		test.Fatalf("Expected 42, saw %d", answer)
+	}
+
+	_, err := Wrap[string](doubled).Get()
+
+	var typeErr *TypeError
+
+	if !errors.As(err, &typeErr) {
+		test.Fatalf("Expected a *TypeError, saw %v", err)
+	}
+
+	mapped := Map(Wrap[string](doubled), func(value string) int {
+		test.Fatalf("Map() must not run for a value of the wrong type")
+
+		return 0
+	})
+
+	if _, err = mapped.Get(); !errors.As(err, &typeErr) {
+		test.Fatalf("Expected Map() to reject with a *TypeError, saw %v", err)
+	}
+
+	expected := errors.New("Expected error!")
+
+	_, err = RejectedOf[int](expected).Then(func(value int) int {
+		test.Fatalf("Then() must not run for a rejected promise")
+
+		return value
+	}).Get()
+
+	if err != expected {
+		test.Fatalf("Expected the cause of the rejection, saw %v", err)
+	}
+}
+
+// Validate that a typed promise may be settled by whichever producer is first,
+// or as another typed promise settles.
+func TestTypedTryComplete(test *testing.T) {
+	promise := PromiseOf[int]()
+
+	if !promise.TryComplete(10) || promise.TryComplete(20) {
+		test.Fatalf("Expected only the first TryComplete() to settle the promise")
+	}
+
+	if promise.TryReject(errors.New("Unexpected error!")) {
+		test.Fatalf("Expected TryReject() not to settle a completed promise")
+	}
+
+	adopting := PromiseOf[int]()
+	adopted := PromiseOf[int]()
+
+	adopting.CompleteWith(adopted.Typed)
+	adopted.Complete(30)
+
+	if value, _ := adopting.Get(); value != 30 {
+		test.Fatalf("Expected 30, saw %d", value)
+	}
+}
@@ -0,0 +1,184 @@
+package promise
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+	"weak"
+)
+
+// Whether or not anything has consumed the outcome of a promise, and if not,
+// whether or not it is being tracked as an unhandled rejection.
+const (
+	unconsumed uint32 = iota
+	tracking
+	consumed
+)
+
+// The hook for rejections which are never handled, which is only called when
+// one has been set, along with the rejected promises being tracked until they
+// either are handled or are reported. The promises are only weakly referenced,
+// so that tracking them does not keep them from being garbage collected.
+var unhandled struct {
+	enabled uint32
+	mutex   sync.Mutex
+	hook    func(Thenable, error)
+	grace   time.Duration
+	pending map[weak.Pointer[CompletablePromise]]struct{}
+}
+
+// Set a hook to be called for every CompletablePromise which is rejected and
+// whose rejection is never handled, returning the previous hook so that it may
+// be restored. A rejection is handled once it has been observed by Get(),
+// Result(), or the like, or once a promise has been composed from the promise
+// which was rejected, such as by Then(), Catch() or Recover(), which carries
+// the rejection along with it.
+//
+// An unhandled rejection is reported when the promise is garbage collected,
+// or once the grace period set by SetUnhandledRejectionGrace() has passed,
+// whichever comes first. There is no hook by default, and setting nil removes
+// it, so that rejections are not tracked at all. Only promises rejected while
+// there is a hook are tracked.
+func OnUnhandledRejection(hook func(Thenable, error)) func(Thenable, error) {
+	unhandled.mutex.Lock()
+
+	defer unhandled.mutex.Unlock()
+
+	previous := unhandled.hook
+
+	unhandled.hook = hook
+
+	if hook != nil {
+		atomic.StoreUint32(&unhandled.enabled, 1)
+	} else {
+		atomic.StoreUint32(&unhandled.enabled, 0)
+	}
+
+	if unhandled.pending == nil {
+		unhandled.pending = make(map[weak.Pointer[CompletablePromise]]struct{})
+	}
+
+	return previous
+}
+
+// Set how long a rejection may go unhandled before it is reported, returning
+// the previous setting. Zero, the default, means that unhandled rejections are
+// only reported when the promise is garbage collected. The grace period is
+// measured with the Clock set by SetClock().
+func SetUnhandledRejectionGrace(grace time.Duration) time.Duration {
+	unhandled.mutex.Lock()
+
+	defer unhandled.mutex.Unlock()
+
+	previous := unhandled.grace
+
+	unhandled.grace = grace
+
+	return previous
+}
+
+// Report every rejection which has not been handled yet, without waiting for
+// the grace period to pass, or for the promises to be garbage collected. This
+// is for checking that nothing was left unhandled, such as at the end of a
+// test.
+func ReportUnhandledRejections() {
+	unhandled.mutex.Lock()
+
+	var promises []*CompletablePromise
+
+	for key := range unhandled.pending {
+		if promise := key.Value(); promise != nil {
+			promises = append(promises, promise)
+		}
+
+		delete(unhandled.pending, key)
+	}
+
+	unhandled.mutex.Unlock()
+
+	for _, promise := range promises {
+		promise.report()
+	}
+}
+
+// Track this promise as an unhandled rejection, if there is a hook to report
+// it to, and nothing has consumed it yet.
+func (promise *CompletablePromise) track() {
+	// Most of the time there's no hook, and no sense in taking the lock.
+	if atomic.LoadUint32(&unhandled.enabled) == 0 {
+		return
+	}
+
+	unhandled.mutex.Lock()
+
+	if unhandled.hook == nil || !atomic.CompareAndSwapUint32(&promise.consumed, unconsumed, tracking) {
+		unhandled.mutex.Unlock()
+
+		return
+	}
+
+	key := weak.Make(promise)
+	grace := unhandled.grace
+
+	unhandled.pending[key] = struct{}{}
+
+	unhandled.mutex.Unlock()
+
+	// Finalizers run on a goroutine of their own, once nothing else refers to
+	// the promise, which is then reachable again for the sake of the hook.
+	runtime.SetFinalizer(promise, func(promise *CompletablePromise) {
+		promise.untrack(key)
+
+		promise.report()
+	})
+
+	if grace > 0 {
+		now().AfterFunc(grace, func() {
+			if promise := key.Value(); promise != nil {
+				promise.untrack(key)
+
+				promise.report()
+			}
+		})
+	}
+}
+
+// Stop tracking this promise.
+func (promise *CompletablePromise) untrack(key weak.Pointer[CompletablePromise]) {
+	unhandled.mutex.Lock()
+
+	delete(unhandled.pending, key)
+
+	unhandled.mutex.Unlock()
+}
+
+// Report this promise as an unhandled rejection, unless it has been handled,
+// or reported, already.
+func (promise *CompletablePromise) report() {
+	if !atomic.CompareAndSwapUint32(&promise.consumed, tracking, consumed) {
+		return
+	}
+
+	unhandled.mutex.Lock()
+
+	hook := unhandled.hook
+
+	unhandled.mutex.Unlock()
+
+	if hook != nil {
+		hook(promise, promise.outcome().cause)
+	}
+}
+
+// Record that the outcome of this promise has been consumed, so that its
+// rejection, if it is rejected, is handled.
+func (promise *CompletablePromise) consume() {
+	if atomic.LoadUint32(&promise.consumed) == consumed {
+		return
+	}
+
+	if atomic.SwapUint32(&promise.consumed, consumed) == tracking {
+		promise.untrack(weak.Make(promise))
+	}
+}
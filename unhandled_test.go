@@ -0,0 +1,148 @@
+package promise
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Collect the unhandled rejections reported while the hook is installed.
+type unhandledRejections struct {
+	mutex  sync.Mutex
+	causes []error
+}
+
+func (rejections *unhandledRejections) install() func() {
+	previous := OnUnhandledRejection(func(thenable Thenable, cause error) {
+		rejections.mutex.Lock()
+
+		rejections.causes = append(rejections.causes, cause)
+
+		rejections.mutex.Unlock()
+	})
+
+	return func() {
+		OnUnhandledRejection(previous)
+	}
+}
+
+func (rejections *unhandledRejections) count() int {
+	rejections.mutex.Lock()
+
+	defer rejections.mutex.Unlock()
+
+	return len(rejections.causes)
+}
+
+// Validate that rejections which nothing handles are reported once the grace
+// period has passed, and that those which are handled are not.
+func TestUnhandledRejection(test *testing.T) {
+	var expected = errors.New("Expected error!")
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	rejections := new(unhandledRejections)
+
+	defer SetClock(SetClock(clock))
+	defer rejections.install()()
+	defer SetUnhandledRejectionGrace(SetUnhandledRejectionGrace(time.Second))
+
+	ignored := Promise()
+	caught := Promise()
+	waited := Promise()
+	late := Promise()
+	chained := Promise()
+	combined := Promise()
+	composed := Promise()
+
+	caught.Catch(func(error) {})
+	dropped := chained.Then(func(value interface{}) interface{} {
+		return value
+	})
+
+	for _, each := range []Completable{ignored, caught, waited, late, chained, combined, composed} {
+		each.Reject(expected)
+	}
+
+	combined.Combine(func(value interface{}) Thenable {
+		return Completed(value)
+	}).Catch(func(error) {})
+
+	// Composing from a promise which was already rejected hands the rejection
+	// on, to be reported if nothing handles it there.
+	composed.Then(func(value interface{}) interface{} {
+		return value
+	})
+
+	waited.Get()
+	late.Recover(func(cause error) (interface{}, error) {
+		return nil, nil
+	})
+
+	clock.Advance(time.Second)
+
+	// Only the promises which were ignored, and the promises derived from
+	// them which were never used, should be reported.
+	if rejections.count() != 3 {
+		test.Fatalf("Expected three unhandled rejections, saw %v", rejections.causes)
+	}
+
+	if !dropped.Rejected() {
+		test.Fatalf("Expected the derived promise to be rejected")
+	}
+
+	cancelled := Promise()
+
+	cancelled.Cancel()
+	Completed(10).Then(func(value interface{}) interface{} {
+		return value
+	})
+
+	clock.Advance(time.Hour)
+
+	if rejections.count() != 3 {
+		test.Fatalf("Expected nothing more to be reported, saw %v", rejections.causes)
+	}
+}
+
+// Validate that a rejection which nothing handles is reported when the promise
+// is garbage collected, and that every rejection still pending can be
+// reported at once.
+func TestUnhandledRejectionCollected(test *testing.T) {
+	var expected = errors.New("Expected error!")
+
+	rejections := new(unhandledRejections)
+
+	defer rejections.install()()
+
+	func() {
+		Promise().Reject(expected)
+	}()
+
+	for i := 0; i < 100 && rejections.count() == 0; i++ {
+		runtime.GC()
+
+		time.Sleep(time.Millisecond)
+	}
+
+	if rejections.count() != 1 {
+		test.Fatalf("Expected the collected promise to be reported, saw %v", rejections.causes)
+	}
+
+	kept := Promise()
+
+	kept.Reject(expected)
+
+	ReportUnhandledRejections()
+
+	if rejections.count() != 2 {
+		test.Fatalf("Expected the pending rejection to be reported, saw %v", rejections.causes)
+	}
+
+	ReportUnhandledRejections()
+
+	if rejections.count() != 2 || kept.Resolved() {
+		test.Fatalf("Expected each rejection to be reported once, saw %v", rejections.causes)
+	}
+}